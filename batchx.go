@@ -3,6 +3,7 @@ package gocqlx
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -65,8 +66,7 @@ func (b *Batch) BindStruct(qry *Queryx, arg interface{}) error {
 	if err != nil {
 		return err
 	}
-	b.Query(qry.Statement(), args...)
-	return nil
+	return b.bindExpanded(qry, args)
 }
 
 // Bind binds query parameters to values from args.
@@ -75,8 +75,7 @@ func (b *Batch) Bind(qry *Queryx, args ...interface{}) error {
 	if len(qry.Names) != len(args) {
 		return fmt.Errorf("query requires %d arguments, but %d provided", len(qry.Names), len(args))
 	}
-	b.Query(qry.Statement(), args...)
-	return nil
+	return b.bindExpanded(qry, args)
 }
 
 // BindMap binds query named parameters to values from arg using a mapper.
@@ -86,8 +85,7 @@ func (b *Batch) BindMap(qry *Queryx, arg map[string]interface{}) error {
 	if err != nil {
 		return err
 	}
-	b.Query(qry.Statement(), args...)
-	return nil
+	return b.bindExpanded(qry, args)
 }
 
 // BindStructMap binds query named parameters to values from arg0 and arg1 using a mapper.
@@ -97,7 +95,19 @@ func (b *Batch) BindStructMap(qry *Queryx, arg0 interface{}, arg1 map[string]int
 	if err != nil {
 		return err
 	}
-	b.Query(qry.Statement(), args...)
+	return b.bindExpanded(qry, args)
+}
+
+func (b *Batch) bindExpanded(qry *Queryx, args []interface{}) error {
+	stmt := qry.Statement()
+	if qry.stmt != "" {
+		stmt = qry.stmt
+	}
+	stmt, _, args, err := qry.expandInArgs(stmt, args)
+	if err != nil {
+		return err
+	}
+	b.Query(stmt, udtWrapSlice(qry.Mapper, qry.Codecs, qry.strict, slices.Clone(args))...)
 	return nil
 }
 
@@ -182,6 +192,7 @@ func (b *Batch) Query(stmt string, args ...interface{}) *Batch {
 // ExecuteBatch executes a batch operation and returns nil if successful
 // otherwise an error describing the failure.
 func (s *Session) ExecuteBatch(batch *Batch) error {
+	s.inspectBatch(batch)
 	return s.Session.ExecuteBatch(batch.Batch)
 }
 
@@ -191,6 +202,7 @@ func (s *Session) ExecuteBatch(batch *Batch) error {
 // Further scans on the interator must also remember to include
 // the applied boolean as the first argument to *Iter.Scan
 func (s *Session) ExecuteBatchCAS(batch *Batch, dest ...interface{}) (applied bool, iter *gocql.Iter, err error) {
+	s.inspectBatch(batch)
 	return s.Session.ExecuteBatchCAS(batch.Batch, dest...)
 }
 
@@ -198,5 +210,12 @@ func (s *Session) ExecuteBatchCAS(batch *Batch, dest ...interface{}) (applied bo
 // however it accepts a map rather than a list of arguments for the initial
 // scan.
 func (s *Session) MapExecuteBatchCAS(batch *Batch, dest map[string]interface{}) (applied bool, iter *gocql.Iter, err error) {
+	s.inspectBatch(batch)
 	return s.Session.MapExecuteBatchCAS(batch.Batch, dest)
 }
+
+func (s *Session) inspectBatch(batch *Batch) {
+	if s.Inspector != nil {
+		s.Inspector.InspectBatch(batch)
+	}
+}
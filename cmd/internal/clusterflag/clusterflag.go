@@ -0,0 +1,80 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+// Package clusterflag defines cluster connection and TLS flags shared by
+// gocqlx commands.
+package clusterflag
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// Flags hold values of the cluster connection flags.
+type Flags struct {
+	Cluster                   string
+	User                      string
+	Password                  string
+	QueryTimeout              time.Duration
+	ConnectionTimeout         time.Duration
+	SSLEnableHostVerification bool
+	SSLCAPath                 string
+	SSLCertPath               string
+	SSLKeyPath                string
+}
+
+// Register defines the cluster connection flags in fs.
+func Register(fs *flag.FlagSet) *Flags {
+	def := gocql.NewCluster()
+
+	f := &Flags{}
+	fs.StringVar(&f.Cluster, "cluster", "127.0.0.1", "a comma-separated list of host:port tuples")
+	fs.StringVar(&f.User, "user", "", "user for password authentication")
+	fs.StringVar(&f.Password, "password", "", "password for password authentication")
+	fs.DurationVar(&f.QueryTimeout, "query-timeout", def.Timeout, "query timeout, i.e. 10s")
+	fs.DurationVar(&f.ConnectionTimeout, "connection-timeout", def.ConnectTimeout, "connection timeout, i.e. 10s")
+	fs.BoolVar(&f.SSLEnableHostVerification, "ssl-enable-host-verification", false, "verify server ssl certificate and host name, ssl is enabled by setting any ssl path flag")
+	fs.StringVar(&f.SSLCAPath, "ssl-ca-path", "", "path to ssl CA certificates")
+	fs.StringVar(&f.SSLCertPath, "ssl-cert-path", "", "path to ssl certificate")
+	fs.StringVar(&f.SSLKeyPath, "ssl-key-path", "", "path to ssl key")
+	return f
+}
+
+// Hosts returns the hosts of the cluster flag.
+func (f *Flags) Hosts() []string {
+	return strings.Split(f.Cluster, ",")
+}
+
+// ClusterConfig returns cluster configuration set up according to the flags.
+func (f *Flags) ClusterConfig() *gocql.ClusterConfig {
+	cluster := gocql.NewCluster(f.Hosts()...)
+
+	if f.User != "" {
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: f.User,
+			Password: f.Password,
+		}
+	}
+
+	if f.QueryTimeout >= 0 {
+		cluster.Timeout = f.QueryTimeout
+	}
+	if f.ConnectionTimeout >= 0 {
+		cluster.ConnectTimeout = f.ConnectionTimeout
+	}
+
+	if f.SSLCAPath != "" || f.SSLCertPath != "" || f.SSLKeyPath != "" {
+		cluster.SslOpts = &gocql.SslOptions{
+			EnableHostVerification: f.SSLEnableHostVerification,
+			CaPath:                 f.SSLCAPath,
+			CertPath:               f.SSLCertPath,
+			KeyPath:                f.SSLKeyPath,
+		}
+	}
+
+	return cluster
+}
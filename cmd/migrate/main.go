@@ -0,0 +1,283 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+// Command migrate applies gocqlx migrations from a directory containing CQL files.
+//
+// Usage:
+//
+//	migrate [flags] <command> <dir> [name]
+//
+// Commands:
+//
+//	up           apply pending migrations
+//	status       list migrations and their progress
+//	plan         print statements that up would execute
+//	mark-applied record migration name as applied without executing it, alias force
+//	baseline     record migrations up to and including name as applied without executing them
+//	lint         check migrations without connecting to the cluster
+//
+// Down migrations are not supported.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/scylladb/gocqlx/v3"
+	"github.com/scylladb/gocqlx/v3/cmd/internal/clusterflag"
+	"github.com/scylladb/gocqlx/v3/migrate"
+)
+
+var (
+	cmd                        = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	flagCluster                = clusterflag.Register(cmd)
+	flagKeyspace               = cmd.String("keyspace", "", "keyspace to use")
+	flagOutOfOrder             = cmd.Bool("out-of-order", false, "apply migrations that sort before already applied ones")
+	flagNamespaced             = cmd.Bool("namespaced", false, "treat subdirectories as independent sequences of migrations")
+	flagVerbose                = cmd.Bool("verbose", false, "log started events as well as finished ones")
+	flagVars                   = varsFlag{}
+	flagInfoKeyspace           = cmd.String("info-keyspace", "", "keyspace of the table recording applied migrations, defaults to keyspace")
+	flagInfoTable              = cmd.String("info-table", migrate.DefaultInfoTable, "table recording applied migrations")
+	flagStatementTimeout       = cmd.Duration("statement-timeout", 0, "timeout of a single migration statement, 0 means no timeout")
+	flagSchemaAgreementTimeout = cmd.Duration("schema-agreement-timeout", 0, "timeout of schema agreement waits, 0 means the session default")
+	flagRetries                = cmd.Int("retries", 0, "number of retries of idempotent statements after timeouts")
+	flagChecksum               = cmd.String("checksum", string(migrate.DefaultChecksumAlgorithm), "checksum algorithm for new migrations: md5, sha256 or sha256norm")
+)
+
+const usage = `Usage: %s [flags] <command> <dir> [name]
+
+Commands:
+  up            apply pending migrations
+  status        list migrations and their progress
+  plan          print statements that up would execute
+  mark-applied  record migration name as applied without executing it, alias force
+  baseline      record migrations up to and including name as applied without executing them
+  lint          check migrations without connecting to the cluster
+
+Flags:
+`
+
+func init() {
+	cmd.Var(flagVars, "var", "template variable in form name=value, can be repeated, enables rendering files with text/template")
+}
+
+// varsFlag collects name=value pairs.
+type varsFlag map[string]string
+
+func (v varsFlag) String() string {
+	return ""
+}
+
+func (v varsFlag) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value got %q", s)
+	}
+	v[name] = value
+	return nil
+}
+
+func main() {
+	cmd.Usage = func() {
+		fmt.Fprintf(cmd.Output(), usage, cmd.Name())
+		cmd.PrintDefaults()
+	}
+	if err := cmd.Parse(os.Args[1:]); err != nil {
+		log.Fatalln("can't parse flags")
+	}
+
+	args := cmd.Args()
+	if len(args) < 2 {
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(context.Background(), args[0], args[1], args[2:]); err != nil {
+		log.Fatalf("%s: %s", args[0], err)
+	}
+}
+
+func run(ctx context.Context, command, dir string, args []string) error {
+	switch command {
+	case "up", "status", "plan", "lint":
+		if len(args) != 0 {
+			return fmt.Errorf("unexpected arguments %s", strings.Join(args, " "))
+		}
+	case "mark-applied", "force", "baseline":
+		if len(args) != 1 {
+			return errors.New("expected migration name")
+		}
+	case "down":
+		return errors.New("down migrations are not supported")
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	f := os.DirFS(dir)
+
+	alg, err := checksumAlgorithm(*flagChecksum)
+	if err != nil {
+		return err
+	}
+	migrate.DefaultChecksumAlgorithm = alg
+	level := slog.LevelInfo
+	if *flagVerbose {
+		level = slog.LevelDebug
+	}
+	m := &migrate.Migrator{
+		Observer:               migrate.SlogObserver(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))),
+		OutOfOrder:             *flagOutOfOrder,
+		Namespaced:             *flagNamespaced,
+		Keyspace:               *flagInfoKeyspace,
+		Table:                  *flagInfoTable,
+		StatementTimeout:       *flagStatementTimeout,
+		SchemaAgreementTimeout: *flagSchemaAgreementTimeout,
+		Retries:                *flagRetries,
+	}
+	if len(flagVars) > 0 {
+		m.TemplateData = map[string]string(flagVars)
+	}
+
+	if command == "lint" {
+		return lint(os.Stdout, f, m)
+	}
+
+	session, err := createSession()
+	if err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+	defer session.Close()
+
+	switch command {
+	case "up":
+		return m.FromFS(ctx, session, f)
+	case "mark-applied", "force":
+		return m.MarkApplied(ctx, session, f, args[0])
+	case "baseline":
+		return m.Baseline(ctx, session, f, args[0])
+	}
+
+	status, err := m.Status(ctx, session, f)
+	if err != nil {
+		return err
+	}
+	if command == "plan" {
+		return printPlan(os.Stdout, status)
+	}
+	return printStatus(os.Stdout, status)
+}
+
+func checksumAlgorithm(s string) (migrate.ChecksumAlgorithm, error) {
+	switch alg := migrate.ChecksumAlgorithm(s); alg {
+	case migrate.ChecksumMD5, migrate.ChecksumSHA256, migrate.ChecksumSHA256Normalized:
+		return alg, nil
+	}
+	return "", fmt.Errorf("unsupported checksum algorithm %q, expected md5, sha256 or sha256norm", s)
+}
+
+func lint(w io.Writer, f fs.FS, m *migrate.Migrator) error {
+	issues, err := (&migrate.Linter{Migrator: m}).Lint(f)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for _, i := range issues {
+		if i.Severity == migrate.LintError {
+			n++
+		}
+		if _, err := fmt.Fprintln(w, i); err != nil {
+			return err
+		}
+	}
+	if n > 0 {
+		return fmt.Errorf("found %d errors", n)
+	}
+	return nil
+}
+
+func printStatus(w io.Writer, status []*migrate.MigrationStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATE\tDONE\tEND TIME")
+	for _, s := range status {
+		endTime := ""
+		if s.Applied != nil {
+			endTime = s.Applied.EndTime.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%s\n", s.Name, state(s), s.Done(), s.Steps(), endTime)
+	}
+	return tw.Flush()
+}
+
+func state(s *migrate.MigrationStatus) string {
+	switch {
+	case s.Squashed:
+		return "squashed"
+	case s.Checksum == "":
+		return "missing"
+	case s.Applied != nil && s.Applied.Checksum != s.Checksum:
+		return "changed"
+	case s.Applied == nil:
+		return "pending"
+	case s.Pending() && s.Applied.Error != "":
+		return "failed"
+	case s.Pending():
+		return "partial"
+	default:
+		return "applied"
+	}
+}
+
+func printPlan(w io.Writer, status []*migrate.MigrationStatus) error {
+	n := 0
+	for _, s := range status {
+		if !s.Pending() {
+			continue
+		}
+		n++
+
+		if s.Func {
+			if _, err := fmt.Fprintf(w, "-- %s: Go code migration\n\n", s.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "-- %s: statements %d-%d\n", s.Name, s.Done()+1, s.Steps()); err != nil {
+			return err
+		}
+		for _, stmt := range s.Statements[s.Done():] {
+			if !strings.HasPrefix(stmt, "--") {
+				stmt += ";"
+			}
+			if _, err := fmt.Fprintln(w, stmt); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	if n == 0 {
+		_, err := fmt.Fprintln(w, "-- nothing to apply")
+		return err
+	}
+	return nil
+}
+
+func createSession() (gocqlx.Session, error) {
+	cluster := flagCluster.ClusterConfig()
+	cluster.Keyspace = *flagKeyspace
+	return gocqlx.WrapSession(cluster.CreateSession())
+}
@@ -0,0 +1,184 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/scylladb/gocqlx/v3/migrate"
+)
+
+func testStatus() []*migrate.MigrationStatus {
+	endTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []*migrate.MigrationStatus{
+		{
+			Applied:    &migrate.Info{Name: "0.cql", Checksum: "a", Done: 1, EndTime: endTime},
+			Name:       "0.cql",
+			Checksum:   "a",
+			Statements: []string{"CREATE TABLE foo (id int PRIMARY KEY)"},
+		},
+		{
+			Applied:    &migrate.Info{Name: "1.cql", Checksum: "b", Done: 1, EndTime: endTime},
+			Name:       "1.cql",
+			Checksum:   "b",
+			Statements: []string{"INSERT INTO foo (id) VALUES (1)", "-- CALL Foo;", "INSERT INTO foo (id) VALUES (2)"},
+		},
+		{
+			Name:     "2_backfill",
+			Checksum: "go",
+			Func:     true,
+		},
+		{
+			Applied:    &migrate.Info{Name: "3.cql", Checksum: "c", Done: 1, EndTime: endTime},
+			Name:       "3.cql",
+			Checksum:   "d",
+			Statements: []string{"SELECT * FROM foo"},
+		},
+		{
+			Applied: &migrate.Info{Name: "4.cql", Checksum: "e", Done: 1, EndTime: endTime},
+			Name:    "4.cql",
+		},
+		{
+			Applied:  &migrate.Info{Name: "5.cql", Checksum: "f", Done: 1, EndTime: endTime},
+			Name:     "5.cql",
+			Squashed: true,
+		},
+		{
+			Applied:    &migrate.Info{Name: "6.cql", Checksum: "g", Done: 1, EndTime: endTime, Error: "timeout"},
+			Name:       "6.cql",
+			Checksum:   "g",
+			Statements: []string{"SELECT * FROM foo", "SELECT * FROM bar"},
+		},
+	}
+}
+
+func TestPrintStatus(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printStatus(&buf, testStatus()); err != nil {
+		t.Fatal(err)
+	}
+
+	golden := `NAME        STATE     DONE  END TIME
+0.cql       applied   1/1   2024-01-02 03:04:05
+1.cql       partial   1/3   2024-01-02 03:04:05
+2_backfill  pending   0/1   
+3.cql       changed   1/1   2024-01-02 03:04:05
+4.cql       missing   1/0   2024-01-02 03:04:05
+5.cql       squashed  1/0   2024-01-02 03:04:05
+6.cql       failed    1/2   2024-01-02 03:04:05
+`
+	if diff := cmp.Diff(golden, buf.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestPrintPlan(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printPlan(&buf, testStatus()); err != nil {
+		t.Fatal(err)
+	}
+
+	golden := `-- 1.cql: statements 2-3
+-- CALL Foo;
+INSERT INTO foo (id) VALUES (2);
+
+-- 2_backfill: Go code migration
+
+-- 6.cql: statements 2-2
+SELECT * FROM bar;
+
+`
+	if diff := cmp.Diff(golden, buf.String()); diff != "" {
+		t.Fatal(diff)
+	}
+
+	buf.Reset()
+	if err := printPlan(&buf, testStatus()[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "-- nothing to apply\n" {
+		t.Fatal(buf.String())
+	}
+}
+
+func TestRunArgs(t *testing.T) {
+	table := []struct {
+		Command string
+		Args    []string
+		Err     string
+	}{
+		{Command: "down", Err: "not supported"},
+		{Command: "bla", Err: "unknown command"},
+		{Command: "up", Args: []string{"x"}, Err: "unexpected arguments"},
+		{Command: "force", Err: "expected migration name"},
+		{Command: "baseline", Err: "expected migration name"},
+	}
+
+	for _, test := range table {
+		err := run(context.Background(), test.Command, t.TempDir(), test.Args)
+		if err == nil || !strings.Contains(err.Error(), test.Err) {
+			t.Fatalf("run(%s) error=%v expected %q", test.Command, err, test.Err)
+		}
+	}
+}
+
+func TestRunChecksum(t *testing.T) {
+	defer func(v string) {
+		*flagChecksum = v
+	}(*flagChecksum)
+
+	*flagChecksum = "sha1"
+	err := run(context.Background(), "up", t.TempDir(), nil)
+	if err == nil || !strings.Contains(err.Error(), "unsupported checksum algorithm") {
+		t.Fatalf("run() error=%v expected unsupported checksum algorithm", err)
+	}
+}
+
+func TestVarsFlag(t *testing.T) {
+	v := varsFlag{}
+	for _, s := range []string{"keyspace=ks", "rf=3", "empty="} {
+		if err := v.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff := cmp.Diff(varsFlag{"keyspace": "ks", "rf": "3", "empty": ""}, v); diff != "" {
+		t.Fatal(diff)
+	}
+	for _, s := range []string{"keyspace", "=ks"} {
+		if err := v.Set(s); err == nil {
+			t.Fatalf("Set(%q) expected error", s)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	f := fstest.MapFS{
+		"0.cql": {Data: []byte("CREATE TABLE IF NOT EXISTS foo (id int PRIMARY KEY);")},
+		"1.cql": {Data: []byte("TRUNCATE foo;")},
+		"2.cql": {Data: []byte("-- empty")},
+		"3.cql": {Data: []byte("")},
+	}
+
+	var buf bytes.Buffer
+	err := lint(&buf, f, &migrate.Migrator{})
+	if err == nil || err.Error() != "found 1 errors" {
+		t.Fatalf("lint() error=%v", err)
+	}
+
+	golden := `1.cql:1: warning: TRUNCATE removes data
+2.cql: warning: only comments found, file is recorded as applied without executing anything
+3.cql: error: no migration statements found
+`
+	if diff := cmp.Diff(golden, buf.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
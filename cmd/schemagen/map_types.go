@@ -77,6 +77,14 @@ func goTypeForScyllaType(s string, allowTuple bool) (goType string, isComparable
 				}
 				return "[]" + t, false, nil
 			}
+		case "vector":
+			if n, err := strconv.Atoi(args[len(args)-1]); len(args) == 2 && err == nil && n > 0 {
+				t, _, err := goTypeForScyllaType(args[0], false)
+				if err != nil {
+					return "", false, err
+				}
+				return "[]" + t, false, nil
+			}
 		case "tuple":
 			if !allowTuple {
 				return "", false, unsupportedTupleElementError(s)
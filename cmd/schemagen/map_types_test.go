@@ -41,6 +41,8 @@ func TestMapScyllaToGoType(t *testing.T) {
 		{"frozen<set<rcas>>", "[]RcasUserType"},
 		{"frozen<map<incidentcustomuserrole, set<userid>>>", "map[IncidentcustomuserroleUserType][]UseridUserType"},
 		{"map<text, frozen<list<album>>>", "map[string][]AlbumUserType"},
+		{"vector<float, 3>", "[]float32"},
+		{"vector<frozen<list<int>>, 2>", "[][]int32"},
 		{"tuple<boolean, int, smallint>", "struct {\n\t\tField1 bool\n\t\tField2 int32\n\t\tField3 int16\n\t}"},
 	}
 	for _, tt := range tests {
@@ -61,6 +63,7 @@ func TestMapScyllaToGoTypeRejectsNonComparableMapKeys(t *testing.T) {
 		"map<blob, text>",
 		"map<decimal, text>",
 		"map<frozen<set<int>>, text>",
+		"map<frozen<vector<float, 3>>, text>",
 	}
 	for _, tt := range tests {
 		t.Run(tt, func(t *testing.T) {
@@ -109,7 +112,8 @@ func TestMapScyllaToGoTypeRejectsExpandedTupleShapes(t *testing.T) {
 
 func TestMapScyllaToGoTypeRejectsUnsupportedConstructors(t *testing.T) {
 	tests := []string{
-		"vector<float, 3>",
+		"vector<float, 0>",
+		"vector<float>",
 		"map<int>",
 		"map<int, text, boolean>",
 		"list<int, text>",
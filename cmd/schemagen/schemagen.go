@@ -17,34 +17,20 @@ import (
 	"github.com/gocql/gocql"
 
 	"github.com/scylladb/gocqlx/v3"
+	"github.com/scylladb/gocqlx/v3/cmd/internal/clusterflag"
 	_ "github.com/scylladb/gocqlx/v3/table"
 )
 
-var defaultClusterConfig = gocql.NewCluster()
-
-var (
-	defaultQueryTimeout      = defaultClusterConfig.Timeout
-	defaultConnectionTimeout = defaultClusterConfig.ConnectTimeout
-)
-
 var (
-	cmd                           = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-	flagCluster                   = cmd.String("cluster", "127.0.0.1", "a comma-separated list of host:port tuples")
-	flagKeyspace                  = cmd.String("keyspace", "", "keyspace to inspect")
-	flagPkgname                   = cmd.String("pkgname", "models", "the name you wish to assign to your generated package")
-	flagOutput                    = cmd.String("output", "models", "the name of the folder to output to")
-	flagOutputDirPerm             = cmd.Uint64("output-dir-perm", 0o755, "output directory permissions")
-	flagOutputFilePerm            = cmd.Uint64("output-file-perm", 0o644, "output file permissions")
-	flagUser                      = cmd.String("user", "", "user for password authentication")
-	flagPassword                  = cmd.String("password", "", "password for password authentication")
-	flagIgnoreNames               = cmd.String("ignore-names", "", "a comma-separated list of table, view or index names to ignore")
-	flagIgnoreIndexes             = cmd.Bool("ignore-indexes", false, "don't generate types for indexes")
-	flagQueryTimeout              = cmd.Duration("query-timeout", defaultQueryTimeout, "query timeout ( in seconds )")
-	flagConnectionTimeout         = cmd.Duration("connection-timeout", defaultConnectionTimeout, "connection timeout ( in seconds )")
-	flagSSLEnableHostVerification = cmd.Bool("ssl-enable-host-verification", false, "don't check server ssl certificate")
-	flagSSLCAPath                 = cmd.String("ssl-ca-path", "", "path to ssl CA certificates")
-	flagSSLCertPath               = cmd.String("ssl-cert-path", "", "path to ssl certificate")
-	flagSSLKeyPath                = cmd.String("ssl-key-path", "", "path to ssl key")
+	cmd                = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	flagCluster        = clusterflag.Register(cmd)
+	flagKeyspace       = cmd.String("keyspace", "", "keyspace to inspect")
+	flagPkgname        = cmd.String("pkgname", "models", "the name you wish to assign to your generated package")
+	flagOutput         = cmd.String("output", "models", "the name of the folder to output to")
+	flagOutputDirPerm  = cmd.Uint64("output-dir-perm", 0o755, "output directory permissions")
+	flagOutputFilePerm = cmd.Uint64("output-file-perm", 0o644, "output file permissions")
+	flagIgnoreNames    = cmd.String("ignore-names", "", "a comma-separated list of table, view or index names to ignore")
+	flagIgnoreIndexes  = cmd.Bool("ignore-indexes", false, "don't generate types for indexes")
 )
 
 //go:embed keyspace.tmpl
@@ -192,36 +178,7 @@ func renderTemplate(md *gocql.KeyspaceMetadata) ([]byte, error) {
 }
 
 func createSession() (gocqlx.Session, error) {
-	cluster := gocql.NewCluster(clusterHosts()...)
-
-	if *flagUser != "" {
-		cluster.Authenticator = gocql.PasswordAuthenticator{
-			Username: *flagUser,
-			Password: *flagPassword,
-		}
-	}
-
-	if *flagQueryTimeout >= 0 {
-		cluster.Timeout = *flagQueryTimeout
-	}
-	if *flagConnectionTimeout >= 0 {
-		cluster.ConnectTimeout = *flagConnectionTimeout
-	}
-
-	if *flagSSLCAPath != "" || *flagSSLCertPath != "" || *flagSSLKeyPath != "" {
-		cluster.SslOpts = &gocql.SslOptions{
-			EnableHostVerification: *flagSSLEnableHostVerification,
-			CaPath:                 *flagSSLCAPath,
-			CertPath:               *flagSSLCertPath,
-			KeyPath:                *flagSSLKeyPath,
-		}
-	}
-
-	return gocqlx.WrapSession(cluster.CreateSession())
-}
-
-func clusterHosts() []string {
-	return strings.Split(*flagCluster, ",")
+	return gocqlx.WrapSession(flagCluster.ClusterConfig().CreateSession())
 }
 
 func existsInSlice(s []string, v string) bool {
@@ -347,7 +304,7 @@ func isComparableScyllaType(s string, userTypes map[string]*gocql.TypeMetadata,
 				}
 			}
 			return true
-		case "map", "set", "list":
+		case "map", "set", "list", "vector":
 			return false
 		default:
 			return true
@@ -389,6 +346,8 @@ func scyllaTypeNames(s string) []string {
 			typeNames = append(typeNames, scyllaTypeNames(arg)...)
 		}
 		return typeNames
+	case "vector":
+		return scyllaTypeNames(args[0])
 	default:
 		return []string{s}
 	}
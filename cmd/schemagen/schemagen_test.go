@@ -523,9 +523,7 @@ func runSchemagen(t *testing.T, pkgname string) []byte {
 		t.Fatal(err)
 	}
 	keyspace := "schemagen"
-	cl := "127.0.1.1"
-
-	flagCluster = &cl
+	flagCluster.Cluster = "127.0.1.1"
 	flagKeyspace = &keyspace
 	flagPkgname = &pkgname
 	flagOutput = &dir
@@ -0,0 +1,129 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package gocqlx
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gocql/gocql"
+)
+
+// Codec marshals and unmarshals values of a Go type that does not implement
+// gocql.Marshaler and gocql.Unmarshaler, i.e. a type from another package.
+type Codec struct {
+	// Marshal returns CQL representation of value, value is of the
+	// registered type.
+	Marshal func(info gocql.TypeInfo, value interface{}) ([]byte, error)
+	// Unmarshal parses CQL representation into value, value is a pointer
+	// to the registered type.
+	Unmarshal func(info gocql.TypeInfo, data []byte, value interface{}) error
+}
+
+// CodecRegistry maps Go types to codecs. Codecs are applied to bound values,
+// scanned values and UDT fields of the registered types and pointers to them.
+//
+// Codecs must be registered before the registry is used by a Session,
+// registry is not safe for concurrent registration.
+type CodecRegistry struct {
+	codecs map[reflect.Type]Codec
+}
+
+// NewCodecRegistry returns an empty CodecRegistry.
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{
+		codecs: make(map[reflect.Type]Codec),
+	}
+}
+
+// Register sets codec for the type of v, v must not be a pointer.
+func (r *CodecRegistry) Register(v interface{}, c Codec) {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() == reflect.Ptr {
+		panic(fmt.Sprintf("gocqlx: can't register codec for %T", v))
+	}
+	if c.Marshal == nil || c.Unmarshal == nil {
+		panic(fmt.Sprintf("gocqlx: incomplete codec for %s", t))
+	}
+	r.codecs[t] = c
+}
+
+// lookup returns the codec for t, *t or **t and the registered type.
+func (r *CodecRegistry) lookup(t reflect.Type) (Codec, reflect.Type, bool) {
+	if r == nil || len(r.codecs) == 0 {
+		return Codec{}, nil, false
+	}
+	for i := 0; i < 3; i++ {
+		if c, ok := r.codecs[t]; ok {
+			return c, t, true
+		}
+		if t.Kind() != reflect.Ptr {
+			break
+		}
+		t = t.Elem()
+	}
+	return Codec{}, nil, false
+}
+
+// has reports whether there is a codec for t.
+func (r *CodecRegistry) has(t reflect.Type) bool {
+	_, _, ok := r.lookup(t)
+	return ok
+}
+
+// wrap adds codec wrapper to v if needed.
+func (r *CodecRegistry) wrap(v interface{}) interface{} {
+	if r == nil || len(r.codecs) == 0 || v == nil {
+		return v
+	}
+	return r.wrapValue(reflect.ValueOf(v))
+}
+
+func (r *CodecRegistry) wrapValue(value reflect.Value) interface{} {
+	if c, t, ok := r.lookup(value.Type()); ok {
+		return codecValue{codec: c, typ: t, value: value}
+	}
+	return value.Interface()
+}
+
+var (
+	_ gocql.Marshaler   = codecValue{}
+	_ gocql.Unmarshaler = codecValue{}
+)
+
+type codecValue struct {
+	codec Codec
+	typ   reflect.Type
+	value reflect.Value
+}
+
+func (c codecValue) MarshalCQL(info gocql.TypeInfo) ([]byte, error) {
+	v := c.value
+	for v.Type() != c.typ {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	return c.codec.Marshal(info, v.Interface())
+}
+
+func (c codecValue) UnmarshalCQL(info gocql.TypeInfo, data []byte) error {
+	v := c.value
+	if v.Kind() != reflect.Ptr {
+		return fmt.Errorf("can't unmarshal into non-pointer %s", v.Type())
+	}
+	for v.Type().Elem() != c.typ {
+		if data == nil {
+			v.Elem().Set(reflect.Zero(v.Type().Elem()))
+			return nil
+		}
+		if v.Elem().IsNil() {
+			v.Elem().Set(reflect.New(v.Type().Elem().Elem()))
+		}
+		v = v.Elem()
+	}
+	return c.codec.Unmarshal(info, data, v.Interface())
+}
@@ -0,0 +1,178 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package gocqlx
+
+import (
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/google/go-cmp/cmp"
+)
+
+var inetCodec = Codec{
+	Marshal: func(info gocql.TypeInfo, value interface{}) ([]byte, error) {
+		return gocql.Marshal(info, net.IP(value.(netip.Addr).AsSlice()))
+	},
+	Unmarshal: func(info gocql.TypeInfo, data []byte, value interface{}) error {
+		var ip net.IP
+		if err := gocql.Unmarshal(info, data, &ip); err != nil {
+			return err
+		}
+		addr, _ := netip.AddrFromSlice(ip)
+		*value.(*netip.Addr) = addr.Unmap()
+		return nil
+	},
+}
+
+func testCodecRegistry() *CodecRegistry {
+	r := NewCodecRegistry()
+	r.Register(netip.Addr{}, inetCodec)
+	return r
+}
+
+var inetInfo = gocql.NewNativeType(4, gocql.TypeInet)
+
+func TestCodecRegistryBind(t *testing.T) {
+	addr := netip.MustParseAddr("10.0.0.1")
+	v := struct {
+		Addr    netip.Addr
+		AddrPtr *netip.Addr
+		NilPtr  *netip.Addr
+		Name    string
+	}{Addr: addr, AddrPtr: &addr, Name: "foo"}
+
+	q := Query(nil, []string{"addr", "addr_ptr", "nil_ptr", "name"})
+	q.Codecs = testCodecRegistry()
+
+	args, err := q.bindStructArgs(v, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := [][]byte{{10, 0, 0, 1}, {10, 0, 0, 1}, nil}
+	for i, g := range golden {
+		b, err := gocql.Marshal(inetInfo, args[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(g, b); diff != "" {
+			t.Errorf("args[%d] %s", i, diff)
+		}
+	}
+	if args[3] != "foo" {
+		t.Errorf("args[3]=%v expected foo", args[3])
+	}
+
+	args, err = q.bindMapArgs(map[string]interface{}{"addr": addr, "addr_ptr": &addr, "nil_ptr": nil, "name": "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := args[0].(codecValue); !ok {
+		t.Errorf("args[0]=%T expected codec wrapper", args[0])
+	}
+}
+
+func TestCodecRegistryBatchBind(t *testing.T) {
+	addr := netip.MustParseAddr("10.0.0.1")
+	session := NewSession(&gocql.Session{})
+	session.Codecs = testCodecRegistry()
+
+	q := session.Query("INSERT INTO t (id, addr) VALUES (?, ?)", []string{"id", "addr"})
+	args := []interface{}{1, addr}
+	b := session.Batch(gocql.LoggedBatch)
+	if err := b.Bind(q, args...); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := b.Entries[0].Args[1].(codecValue); !ok {
+		t.Errorf("Args[1]=%T expected codec wrapper", b.Entries[0].Args[1])
+	}
+	if args[1] != addr {
+		t.Errorf("Bind() modified args")
+	}
+
+	if err := b.BindMap(q, map[string]interface{}{"id": 2, "addr": addr}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := b.Entries[1].Args[1].(codecValue); !ok {
+		t.Errorf("Args[1]=%T expected codec wrapper", b.Entries[1].Args[1])
+	}
+}
+
+func TestCodecRegistryScan(t *testing.T) {
+	r := testCodecRegistry()
+	data := []byte{10, 0, 0, 1}
+	golden := netip.MustParseAddr("10.0.0.1")
+
+	t.Run("value", func(t *testing.T) {
+		var v netip.Addr
+		if err := gocql.Unmarshal(inetInfo, data, r.wrap(&v)); err != nil {
+			t.Fatal(err)
+		}
+		if v != golden {
+			t.Fatalf("Unmarshal()=%s expected %s", v, golden)
+		}
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		var v *netip.Addr
+		if err := gocql.Unmarshal(inetInfo, data, r.wrap(&v)); err != nil {
+			t.Fatal(err)
+		}
+		if v == nil || *v != golden {
+			t.Fatalf("Unmarshal()=%v expected %s", v, golden)
+		}
+		if err := gocql.Unmarshal(inetInfo, nil, r.wrap(&v)); err != nil {
+			t.Fatal(err)
+		}
+		if v != nil {
+			t.Fatalf("Unmarshal()=%v expected nil", v)
+		}
+	})
+
+	t.Run("scannable", func(t *testing.T) {
+		iter := &Iterx{Mapper: DefaultMapper, Codecs: r}
+		if !iter.isScannable(reflect.TypeOf(golden)) {
+			t.Fatal("expected type with codec to be scannable")
+		}
+	})
+}
+
+func TestCodecRegistryUDT(t *testing.T) {
+	type Host struct {
+		UDT
+		Addr netip.Addr
+	}
+	r := testCodecRegistry()
+	info := gocql.UDTTypeInfo{
+		NativeType: gocql.NewNativeType(4, gocql.TypeUDT),
+		Name:       "host",
+		Elements:   []gocql.UDTField{{Name: "addr", Type: inetInfo}},
+	}
+
+	in := Host{Addr: netip.MustParseAddr("10.0.0.1")}
+	b, err := gocql.Marshal(info, udtWrapValue(reflect.ValueOf(in), DefaultMapper, r, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out Host
+	if err := gocql.Unmarshal(info, b, udtWrapValue(reflect.ValueOf(&out), DefaultMapper, r, false)); err != nil {
+		t.Fatal(err)
+	}
+	if out.Addr != in.Addr {
+		t.Fatalf("Unmarshal()=%s expected %s", out.Addr, in.Addr)
+	}
+}
+
+func TestCodecRegistryRegisterPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	NewCodecRegistry().Register(&netip.Addr{}, inetCodec)
+}
@@ -0,0 +1,263 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package gocqlx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// InExpansionLimit is the maximum number of expanded variants of a single
+// statement, see Queryx.ExpandIn. Every variant is a separate prepared
+// statement, when the limit is reached slices are bound as a whole to the
+// IN ? parameter.
+var InExpansionLimit = 16
+
+// maxExpandedStatements bounds the number of statements in the expansion
+// cache, the cache is cleared when it's exceeded.
+const maxExpandedStatements = 1000
+
+type inCache struct {
+	mu    sync.Mutex
+	stmts map[string]map[string]string
+}
+
+var expandedStmts = inCache{
+	stmts: make(map[string]map[string]string),
+}
+
+// expand returns stmt with the placeholders expanded to the given lengths,
+// lengths maps placeholder index to the number of placeholders.
+// If InExpansionLimit variants of stmt are already cached it returns false.
+func (c *inCache) expand(stmt string, lengths map[int]int, count int) (string, bool, error) {
+	key := expansionKey(lengths, count)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	variants, ok := c.stmts[stmt]
+	if ok {
+		if v, ok := variants[key]; ok {
+			return v, true, nil
+		}
+		if len(variants) >= InExpansionLimit {
+			return stmt, false, nil
+		}
+	}
+
+	v, err := expandPlaceholders(stmt, lengths)
+	if err != nil {
+		return "", false, err
+	}
+	if variants == nil {
+		if len(c.stmts) >= maxExpandedStatements {
+			c.stmts = make(map[string]map[string]string)
+		}
+		variants = make(map[string]string)
+		c.stmts[stmt] = variants
+	}
+	variants[key] = v
+	return v, true, nil
+}
+
+func expansionKey(lengths map[int]int, count int) string {
+	var b []byte
+	for i := 0; i < count; i++ {
+		if n, ok := lengths[i]; ok {
+			b = strconv.AppendInt(b, int64(n), 10)
+		}
+		b = append(b, ',')
+	}
+	return string(b)
+}
+
+// placeholders returns offsets of '?' placeholders in stmt, placeholders
+// inside string literals, $$ strings, quoted identifiers and comments are
+// ignored as by the qb lexer.
+func placeholders(stmt string) []int {
+	var offsets []int
+	for i := 0; i < len(stmt); i++ {
+		var start, end string
+		switch rest := stmt[i:]; {
+		case rest[0] == '?':
+			offsets = append(offsets, i)
+			continue
+		case rest[0] == '\'' || rest[0] == '"':
+			start, end = rest[:1], rest[:1]
+		case strings.HasPrefix(rest, "$$"):
+			start, end = "$$", "$$"
+		case strings.HasPrefix(rest, "--"), strings.HasPrefix(rest, "//"):
+			start, end = rest[:2], "\n"
+		case strings.HasPrefix(rest, "/*"):
+			start, end = "/*", "*/"
+		default:
+			continue
+		}
+		n := strings.Index(stmt[i+len(start):], end)
+		if n < 0 {
+			break
+		}
+		i += len(start) + n + len(end) - 1
+	}
+	return offsets
+}
+
+// expandPlaceholders replaces '?' placeholders at the indexes of lengths
+// with (?,?,...) lists.
+func expandPlaceholders(stmt string, lengths map[int]int) (string, error) {
+	var (
+		buf  bytes.Buffer
+		last int
+	)
+	offsets := placeholders(stmt)
+	for idx, off := range offsets {
+		n, ok := lengths[idx]
+		if !ok {
+			continue
+		}
+		buf.WriteString(stmt[last:off])
+		buf.WriteByte('(')
+		for j := 0; j < n; j++ {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('?')
+		}
+		buf.WriteByte(')')
+		last = off + 1
+	}
+	buf.WriteString(stmt[last:])
+
+	idx := len(offsets)
+	for i := range lengths {
+		if i >= idx {
+			return "", fmt.Errorf("statement has %d placeholders, can't expand placeholder %d", idx, i+1)
+		}
+	}
+	return buf.String(), nil
+}
+
+// ExpandIn sets names of the parameters bound to IN ? that are expanded to
+// IN (?,?,...) with a placeholder for every element of the bound slice,
+// i.e. binding []int{1, 2, 3} to "id IN ?" produces "id IN (?,?,?)".
+// It must be called before binding, all the Bind methods of Queryx and Batch
+// expand the parameters. Settings made with Queryx methods are kept when the
+// statement is replaced. Expanded statements are cached, see
+// InExpansionLimit.
+func (q *Queryx) ExpandIn(names ...string) *Queryx {
+	q.expandIn = append(q.expandIn, names...)
+	if q.stmt == "" {
+		q.stmt = q.Query.Statement()
+	}
+	return q
+}
+
+// expandInArgs returns the statement, names and arguments with the ExpandIn
+// parameters expanded.
+func (q *Queryx) expandInArgs(stmt string, arglist []interface{}) (string, []string, []interface{}, error) {
+	if len(q.expandIn) == 0 {
+		return stmt, q.Names, arglist, nil
+	}
+	if len(arglist) != len(q.Names) {
+		return "", nil, nil, fmt.Errorf("can't expand IN parameters: got %d values for %d names", len(arglist), len(q.Names))
+	}
+
+	var lengths map[int]int
+	for i, name := range q.Names {
+		if !q.expanded(name) {
+			continue
+		}
+		v := reflect.ValueOf(arglist[i])
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return "", nil, nil, fmt.Errorf("can't expand %q: expected a slice but got %T", name, arglist[i])
+		}
+		if lengths == nil {
+			lengths = make(map[int]int)
+		}
+		lengths[i] = v.Len()
+	}
+	if lengths == nil {
+		return stmt, q.Names, arglist, nil
+	}
+
+	expanded, ok, err := expandedStmts.expand(stmt, lengths, len(q.Names))
+	if err != nil || !ok {
+		return stmt, q.Names, arglist, err
+	}
+
+	names := make([]string, 0, len(arglist))
+	args := make([]interface{}, 0, len(arglist))
+	for i, a := range arglist {
+		if _, ok := lengths[i]; !ok {
+			names = append(names, q.Names[i])
+			args = append(args, a)
+			continue
+		}
+		v := reflect.ValueOf(a)
+		for j := 0; j < v.Len(); j++ {
+			names = append(names, q.Names[i])
+			args = append(args, q.Codecs.wrap(v.Index(j).Interface()))
+		}
+	}
+	return expanded, names, args, nil
+}
+
+func (q *Queryx) expanded(name string) bool {
+	for _, n := range q.expandIn {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bindExpanded expands the ExpandIn parameters and binds the arguments.
+func (q *Queryx) bindExpanded(arglist []interface{}) error {
+	if len(q.expandIn) == 0 {
+		q.bind(arglist)
+		return nil
+	}
+
+	stmt, names, args, err := q.expandInArgs(q.stmt, arglist)
+	if err != nil {
+		return err
+	}
+	q.expandedNames = names
+	if stmt != q.Query.Statement() {
+		if err := q.setStatement(stmt); err != nil {
+			return err
+		}
+	}
+	q.bind(args)
+	return nil
+}
+
+// setStatement replaces the query with a query of the same session with
+// the given statement. Context, consistency, idempotence, request timeout,
+// host and the settings recorded by Queryx wrappers are preserved, settings
+// made directly on the embedded gocql.Query are lost.
+func (q *Queryx) setStatement(stmt string) error {
+	old := q.Query
+	s := old.GetSession()
+	if s == nil {
+		return errors.New("can't expand IN parameters of a query without a session")
+	}
+	q.Query = s.Query(stmt).
+		WithContext(old.Context()).
+		Consistency(old.GetConsistency()).
+		Idempotent(old.IsIdempotent()).
+		SetRequestTimeout(old.GetRequestTimeout()).
+		SetHostID(old.GetHostID())
+	for _, fn := range q.settings {
+		fn(q.Query)
+	}
+	old.Release()
+	return nil
+}
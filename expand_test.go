@@ -0,0 +1,195 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package gocqlx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpandPlaceholders(t *testing.T) {
+	table := []struct {
+		Name    string
+		Stmt    string
+		Lengths map[int]int
+		Golden  string
+		Err     bool
+	}{
+		{
+			Name:    "single",
+			Stmt:    "SELECT * FROM t WHERE id IN ? ",
+			Lengths: map[int]int{0: 3},
+			Golden:  "SELECT * FROM t WHERE id IN (?,?,?) ",
+		},
+		{
+			Name:    "empty",
+			Stmt:    "SELECT * FROM t WHERE id IN ? ",
+			Lengths: map[int]int{0: 0},
+			Golden:  "SELECT * FROM t WHERE id IN () ",
+		},
+		{
+			Name:    "multiple",
+			Stmt:    "SELECT * FROM t WHERE a IN ? AND b=? AND c IN ? LIMIT ?",
+			Lengths: map[int]int{0: 2, 2: 1},
+			Golden:  "SELECT * FROM t WHERE a IN (?,?) AND b=? AND c IN (?) LIMIT ?",
+		},
+		{
+			Name:    "quoted",
+			Stmt:    `SELECT "?" FROM t WHERE a='?' AND b IN ? -- ?` + "\nAND c=?",
+			Lengths: map[int]int{0: 2},
+			Golden:  `SELECT "?" FROM t WHERE a='?' AND b IN (?,?) -- ?` + "\nAND c=?",
+		},
+		{
+			Name:    "comments",
+			Stmt:    "SELECT /* ? */ * FROM t // ?\nWHERE a IN ? AND b=$$?$$ AND c IN ? -- ?",
+			Lengths: map[int]int{0: 2, 1: 1},
+			Golden:  "SELECT /* ? */ * FROM t // ?\nWHERE a IN (?,?) AND b=$$?$$ AND c IN (?) -- ?",
+		},
+		{
+			Name:    "unterminated",
+			Stmt:    "SELECT * FROM t WHERE a IN ? AND b='?",
+			Lengths: map[int]int{0: 2},
+			Golden:  "SELECT * FROM t WHERE a IN (?,?) AND b='?",
+		},
+		{
+			Name:    "missing placeholder",
+			Stmt:    "SELECT * FROM t WHERE id IN ?",
+			Lengths: map[int]int{1: 2},
+			Err:     true,
+		},
+	}
+
+	for i := range table {
+		test := table[i]
+		t.Run(test.Name, func(t *testing.T) {
+			stmt, err := expandPlaceholders(test.Stmt, test.Lengths)
+			if test.Err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.Golden, stmt); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestInCacheLimit(t *testing.T) {
+	defer func(v int) {
+		InExpansionLimit = v
+	}(InExpansionLimit)
+	InExpansionLimit = 2
+
+	c := inCache{stmts: make(map[string]map[string]string)}
+	const stmt = "SELECT * FROM t WHERE id IN ?"
+
+	for _, n := range []int{1, 2, 1} {
+		if _, ok, err := c.expand(stmt, map[int]int{0: n}, 1); err != nil || !ok {
+			t.Fatalf("expand(%d) ok=%v err=%v", n, ok, err)
+		}
+	}
+	v, ok, err := c.expand(stmt, map[int]int{0: 3}, 1)
+	if err != nil || ok || v != stmt {
+		t.Fatalf("expand(3)=%q ok=%v err=%v expected limit", v, ok, err)
+	}
+}
+
+func TestQueryxExpandInArgs(t *testing.T) {
+	const stmt = "SELECT * FROM t WHERE pk IN ? AND ck=? "
+	q := Query(nil, []string{"pk", "ck"})
+	q.expandIn = []string{"pk"}
+
+	v := struct {
+		PK []int
+		CK string
+	}{PK: []int{1, 2, 3}, CK: "foo"}
+
+	arglist, err := q.bindStructArgs(v, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, names, args, err := q.expandInArgs(stmt, arglist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("SELECT * FROM t WHERE pk IN (?,?,?) AND ck=? ", s); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]string{"pk", "pk", "pk", "ck"}, names); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]interface{}{1, 2, 3, "foo"}, args); diff != "" {
+		t.Error(diff)
+	}
+
+	t.Run("not a slice", func(t *testing.T) {
+		if _, _, _, err := q.expandInArgs(stmt, []interface{}{1, "foo"}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestQueryxExpandInSettings(t *testing.T) {
+	const stmt = "SELECT * FROM t WHERE pk IN ? AND ck=? "
+	rt := &gocql.SimpleRetryPolicy{NumRetries: 7}
+	session := NewSession(&gocql.Session{})
+
+	q := session.Query(stmt, []string{"pk", "ck"}).
+		PageSize(7).
+		RetryPolicy(rt).
+		ExpandIn("pk").
+		BindMap(map[string]interface{}{"pk": []int{1, 2}, "ck": "foo"})
+	if err := q.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("SELECT * FROM t WHERE pk IN (?,?) AND ck=? ", q.Statement()); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// gocql.Query has no getters for the settings
+	v := reflect.ValueOf(q.Query).Elem()
+	if n := v.FieldByName("pageSize").Int(); n != 7 {
+		t.Errorf("pageSize=%d expected 7", n)
+	}
+	if p := v.FieldByName("rt"); p.IsNil() || p.Elem().Pointer() != reflect.ValueOf(rt).Pointer() {
+		t.Error("retry policy was not preserved")
+	}
+}
+
+func TestQueryxExpandInBind(t *testing.T) {
+	const stmt = "SELECT * FROM t WHERE pk IN ? AND ck=? "
+	session := NewSession(&gocql.Session{})
+
+	q := session.Query(stmt, []string{"pk", "ck"}).ExpandIn("pk").Bind([]int{1, 2, 3}, "foo")
+	if err := q.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("SELECT * FROM t WHERE pk IN (?,?,?) AND ck=? ", q.Statement()); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]interface{}{1, 2, 3, "foo"}, q.Values()); diff != "" {
+		t.Error(diff)
+	}
+
+	if err := q.Bind([]int{1}).Err(); err == nil {
+		t.Error("expected error")
+	}
+
+	b := session.NewBatch(gocql.LoggedBatch)
+	if err := b.Bind(session.Query(stmt, []string{"pk", "ck"}).ExpandIn("pk"), []int{1, 2}, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("SELECT * FROM t WHERE pk IN (?,?) AND ck=? ", b.Entries[0].Stmt); diff != "" {
+		t.Error(diff)
+	}
+}
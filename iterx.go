@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 
 	"github.com/gocql/gocql"
 	"github.com/scylladb/go-reflectx"
@@ -22,8 +23,11 @@ type Iterx struct {
 	err error
 	*gocql.Iter
 	Mapper *reflectx.Mapper
+	Codecs *CodecRegistry
+	tr     ScanTransformer
 
 	// Cache memory for a rows during iteration in structScan.
+	columns    []string
 	fields     [][]int
 	values     []interface{}
 	strict     bool
@@ -39,6 +43,14 @@ func (iter *Iterx) Strict() *Iterx {
 	return iter
 }
 
+// WithScanTransformer sets the iterator scan transformer.
+// The transformer is called for every column right after it's scanned into
+// a struct field, a single column destination or a map.
+func (iter *Iterx) WithScanTransformer(tr ScanTransformer) *Iterx {
+	iter.tr = tr
+	return iter
+}
+
 // StructOnly forces the iterator to treat a single-argument struct as
 // non-scannable. This is is useful if you need to scan a row into a struct
 // that also implements gocql.UDTUnmarshaler or in rare cases gocql.Unmarshaler.
@@ -206,6 +218,7 @@ func (iter *Iterx) scanAll(dest interface{}) bool {
 // isScannable takes the reflect.Type and the actual dest value and returns
 // whether or not it's Scannable. t is scannable if:
 //   - ptr to t implements gocql.Unmarshaler, gocql.UDTUnmarshaler or UDT
+//   - t has a registered codec
 //   - it is not a struct
 //   - it has no exported fields
 func (iter *Iterx) isScannable(t reflect.Type) bool {
@@ -217,6 +230,8 @@ func (iter *Iterx) isScannable(t reflect.Type) bool {
 		return true
 	case ptr.Implements(autoUDTInterface):
 		return true
+	case iter.Codecs.has(t):
+		return true
 	case t.Kind() != reflect.Struct:
 		return true
 	default:
@@ -228,7 +243,16 @@ func (iter *Iterx) scan(value reflect.Value) bool {
 	if value.Kind() != reflect.Ptr {
 		panic("value must be a pointer")
 	}
-	return iter.Iter.Scan(udtWrapValue(value, iter.Mapper, iter.strict))
+	if !iter.Iter.Scan(udtWrapValue(value, iter.Mapper, iter.Codecs, iter.strict)) {
+		return false
+	}
+	if iter.tr != nil {
+		if err := transformScanned(iter.tr, iter.Columns()[0].Name, value.Elem()); err != nil {
+			iter.err = err
+			return false
+		}
+	}
+	return true
 }
 
 // StructScan is like gocql.Iter.Scan, but scans a single row into a single
@@ -263,6 +287,7 @@ func (iter *Iterx) structScan(value reflect.Value) bool {
 		columns := columnNames(iter.Columns())
 		cas := len(columns) > 0 && columns[0] == appliedColumn
 
+		iter.columns = columns
 		iter.fields = iter.Mapper.TraversalsByName(value.Type(), columns)
 		// if we are strict and it's not CAS query and are missing fields, return an error
 		if iter.strict && !cas {
@@ -283,7 +308,31 @@ func (iter *Iterx) structScan(value reflect.Value) bool {
 	}
 
 	// scan into the struct field pointers and append to our results
-	return iter.Iter.Scan(iter.values...)
+	if !iter.Iter.Scan(iter.values...) {
+		return false
+	}
+	if iter.tr != nil {
+		if err := iter.transformFields(value); err != nil {
+			iter.err = err
+			return false
+		}
+	}
+	return true
+}
+
+// transformFields applies the scan transformer to the struct fields mapped
+// to columns.
+func (iter *Iterx) transformFields(value reflect.Value) error {
+	value = reflect.Indirect(value)
+	for i, traversal := range iter.fields {
+		if len(traversal) == 0 {
+			continue
+		}
+		if err := transformScanned(iter.tr, iter.columns[i], reflectx.FieldByIndexes(value, traversal)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // fieldsByName fills a values interface with fields from the passed value based
@@ -302,7 +351,7 @@ func (iter *Iterx) fieldsByTraversal(value reflect.Value, traversals [][]int, va
 			continue
 		}
 		f := reflectx.FieldByIndexes(value, traversal).Addr()
-		values[i] = udtWrapValue(f, iter.Mapper, iter.strict)
+		values[i] = udtWrapValue(f, iter.Mapper, iter.Codecs, iter.strict)
 	}
 
 	return nil
@@ -323,9 +372,57 @@ func columnNames(ci []gocql.ColumnInfo) []string {
 //
 // Scan returns true if the row was successfully unmarshaled or false if the
 // end of the result set was reached or if an error occurred. Close should
-// be called afterwards to retrieve any potential errors.
+// be called afterwards to retrieve any potential errors. The scan
+// transformer is applied to every non nil dest value except the [applied]
+// column of a lightweight transaction.
 func (iter *Iterx) Scan(dest ...interface{}) bool {
-	return iter.Iter.Scan(udtWrapSlice(iter.Mapper, iter.strict, dest)...)
+	if !iter.Iter.Scan(udtWrapSlice(iter.Mapper, iter.Codecs, iter.strict, slices.Clone(dest))...) {
+		return false
+	}
+	if iter.tr != nil {
+		columns := iter.Columns()
+		for i, d := range dest {
+			v := reflect.ValueOf(d)
+			if i >= len(columns) || columns[i].Name == appliedColumn || v.Kind() != reflect.Ptr || v.IsNil() {
+				continue
+			}
+			if err := transformScanned(iter.tr, columns[i].Name, v.Elem()); err != nil {
+				iter.err = err
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MapScan takes a map[string]interface{} and populates it with a row
+// that is returned from cassandra, the scan transformer is applied to every
+// column except the [applied] column of a lightweight transaction.
+func (iter *Iterx) MapScan(m map[string]interface{}) bool {
+	if !iter.Iter.MapScan(m) {
+		return false
+	}
+	if iter.tr != nil {
+		if err := iter.transformMap(m); err != nil {
+			iter.err = err
+			return false
+		}
+	}
+	return true
+}
+
+func (iter *Iterx) transformMap(m map[string]interface{}) error {
+	for name, val := range m {
+		if name == appliedColumn {
+			continue
+		}
+		v, err := iter.tr(name, val)
+		if err != nil {
+			return fmt.Errorf("transform column %q: %w", name, err)
+		}
+		m[name] = v
+	}
+	return nil
 }
 
 // Close closes the iterator and returns any errors that happened during
@@ -8,7 +8,10 @@
 package gocqlx_test
 
 import (
+	"errors"
 	"math/big"
+	"net"
+	"net/netip"
 	"reflect"
 	"strings"
 	"testing"
@@ -22,6 +25,7 @@ import (
 	"github.com/scylladb/gocqlx/v3"
 	"github.com/scylladb/gocqlx/v3/gocqlxtest"
 	"github.com/scylladb/gocqlx/v3/qb"
+	"github.com/scylladb/gocqlx/v3/table"
 )
 
 type FullName struct {
@@ -1013,3 +1017,454 @@ func TestIterxCAS(t *testing.T) {
 		t.Error("GetCAS()=%=v expected to have pre-image", john)
 	}
 }
+
+func TestIterxScanTransformer(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+
+	if err := session.ExecStmt(`CREATE TABLE gocqlx_test.codec_table (id int PRIMARY KEY, secret text)`); err != nil {
+		t.Fatal("create table:", err)
+	}
+
+	reverse := func(val interface{}) string {
+		r := []rune(val.(string))
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r)
+	}
+	codecs := gocqlx.ColumnCodecs{
+		"secret": {
+			Bind: func(val interface{}) interface{} { return reverse(val) },
+			Scan: func(val interface{}) (interface{}, error) { return reverse(val), nil },
+		},
+	}
+
+	type Secret struct {
+		ID     int
+		Secret string
+	}
+	v := Secret{ID: 1, Secret: "gocqlx"}
+
+	insert := session.Query(qb.Insert("codec_table").Columns("id", "secret").ToCql())
+	if err := insert.WithColumnCodecs(codecs).BindStruct(v).ExecRelease(); err != nil {
+		t.Fatal("insert:", err)
+	}
+
+	t.Run("stored", func(t *testing.T) {
+		var secret string
+		if err := session.Query(`SELECT secret FROM codec_table WHERE id = 1`, nil).Get(&secret); err != nil {
+			t.Fatal("Get() failed:", err)
+		}
+		if secret != "xlqcog" {
+			t.Fatalf("Get()=%q expected %q", secret, "xlqcog")
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		var got Secret
+		if err := session.Query(`SELECT * FROM codec_table`, nil).WithColumnCodecs(codecs).Get(&got); err != nil {
+			t.Fatal("Get() failed:", err)
+		}
+		diff(t, v, got)
+	})
+
+	t.Run("select scannable", func(t *testing.T) {
+		var got []string
+		if err := session.Query(`SELECT secret FROM codec_table`, nil).WithColumnCodecs(codecs).Select(&got); err != nil {
+			t.Fatal("Select() failed:", err)
+		}
+		diff(t, []string{v.Secret}, got)
+	})
+
+	t.Run("map scan", func(t *testing.T) {
+		iter := session.Query(`SELECT * FROM codec_table`, nil).Iter().WithScanTransformer(codecs.ScanTransformer())
+		m := map[string]interface{}{}
+		if !iter.MapScan(m) {
+			t.Fatal("MapScan() failed:", iter.Close())
+		}
+		if err := iter.Close(); err != nil {
+			t.Fatal("Close() failed:", err)
+		}
+		diff(t, map[string]interface{}{"id": 1, "secret": v.Secret}, m)
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		var id int
+		var secret string
+		if err := session.Query(`SELECT id, secret FROM codec_table`, nil).WithColumnCodecs(codecs).Scan(&id, &secret); err != nil {
+			t.Fatal("Scan() failed:", err)
+		}
+		diff(t, v.Secret, secret)
+
+		iter := session.Query(`SELECT id, secret FROM codec_table`, nil).WithColumnCodecs(codecs).Iter()
+		secret = ""
+		if !iter.Scan(nil, &secret) {
+			t.Fatal("Scan() failed:", iter.Close())
+		}
+		if err := iter.Close(); err != nil {
+			t.Fatal("Close() failed:", err)
+		}
+		diff(t, v.Secret, secret)
+	})
+
+	t.Run("query map scan", func(t *testing.T) {
+		m := map[string]interface{}{}
+		if err := session.Query(`SELECT * FROM codec_table`, nil).WithColumnCodecs(codecs).MapScan(m); err != nil {
+			t.Fatal("MapScan() failed:", err)
+		}
+		diff(t, map[string]interface{}{"id": 1, "secret": v.Secret}, m)
+	})
+
+	t.Run("scan cas", func(t *testing.T) {
+		q := session.Query(`INSERT INTO codec_table (id, secret) VALUES (1, 'foo') IF NOT EXISTS`, nil).WithColumnCodecs(codecs)
+		var id int
+		var secret string
+		applied, err := q.ScanCAS(&id, &secret)
+		if err != nil {
+			t.Fatal("ScanCAS() failed:", err)
+		}
+		if applied {
+			t.Fatal("ScanCAS() applied")
+		}
+		diff(t, v.Secret, secret)
+
+		m := map[string]interface{}{}
+		q = session.Query(`INSERT INTO codec_table (id, secret) VALUES (1, 'foo') IF NOT EXISTS`, nil).WithColumnCodecs(codecs)
+		applied, err = q.MapScanCAS(m)
+		if err != nil {
+			t.Fatal("MapScanCAS() failed:", err)
+		}
+		if applied {
+			t.Fatal("MapScanCAS() applied")
+		}
+		diff(t, map[string]interface{}{"id": 1, "secret": v.Secret}, m)
+	})
+
+	t.Run("session and codecs", func(t *testing.T) {
+		s := session
+		s.ScanTransformer = func(name string, val interface{}) (interface{}, error) {
+			if s, ok := val.(string); ok {
+				return s + "!", nil
+			}
+			return val, nil
+		}
+		var secret string
+		if err := s.Query(`SELECT secret FROM codec_table`, nil).WithColumnCodecs(codecs).Get(&secret); err != nil {
+			t.Fatal("Get() failed:", err)
+		}
+		diff(t, "!"+v.Secret, secret)
+	})
+
+	t.Run("session default", func(t *testing.T) {
+		s := session
+		s.ScanTransformer = func(name string, val interface{}) (interface{}, error) {
+			return nil, errors.New("boom")
+		}
+		var got Secret
+		err := s.Query(`SELECT * FROM codec_table`, nil).Get(&got)
+		if err == nil || err.Error() != `transform column "id": boom` {
+			t.Fatalf("Get() error=%v", err)
+		}
+	})
+}
+
+func TestIterxCodecs(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+
+	if err := session.ExecStmt(`CREATE TYPE gocqlx_test.codec_host (addr inet)`); err != nil {
+		t.Fatal("create type:", err)
+	}
+	if err := session.ExecStmt(`CREATE TABLE gocqlx_test.codec_hosts (id int PRIMARY KEY, addr inet, backup inet, host codec_host)`); err != nil {
+		t.Fatal("create table:", err)
+	}
+
+	session.Codecs = gocqlx.NewCodecRegistry()
+	session.Codecs.Register(netip.Addr{}, gocqlx.Codec{
+		Marshal: func(info gocql.TypeInfo, value interface{}) ([]byte, error) {
+			return gocql.Marshal(info, net.IP(value.(netip.Addr).AsSlice()))
+		},
+		Unmarshal: func(info gocql.TypeInfo, data []byte, value interface{}) error {
+			var ip net.IP
+			if err := gocql.Unmarshal(info, data, &ip); err != nil {
+				return err
+			}
+			addr, _ := netip.AddrFromSlice(ip)
+			*value.(*netip.Addr) = addr.Unmap()
+			return nil
+		},
+	})
+
+	type Host struct {
+		gocqlx.UDT
+		Addr netip.Addr
+	}
+	type Row struct {
+		ID     int
+		Addr   netip.Addr
+		Backup *netip.Addr
+		Host   Host
+	}
+	addr := netip.MustParseAddr("10.0.0.1")
+	v := Row{ID: 1, Addr: addr, Host: Host{Addr: addr}}
+
+	insert := session.Query(qb.Insert("codec_hosts").Columns("id", "addr", "backup", "host").ToCql())
+	if err := insert.BindStruct(v).ExecRelease(); err != nil {
+		t.Fatal("insert:", err)
+	}
+
+	var got Row
+	if err := session.Query(`SELECT * FROM codec_hosts`, nil).Get(&got); err != nil {
+		t.Fatal("Get() failed:", err)
+	}
+	if d := cmp.Diff(v, got, cmpopts.EquateComparable(netip.Addr{})); d != "" {
+		t.Fatal("Get()", d)
+	}
+
+	var addrs []netip.Addr
+	if err := session.Query(`SELECT addr FROM codec_hosts`, nil).Select(&addrs); err != nil {
+		t.Fatal("Select() failed:", err)
+	}
+	if d := cmp.Diff([]netip.Addr{addr}, addrs, cmpopts.EquateComparable(netip.Addr{})); d != "" {
+		t.Fatal("Select()", d)
+	}
+
+	t.Run("scan transformer", func(t *testing.T) {
+		next := func(name string, val interface{}) (interface{}, error) {
+			if name == "addr" {
+				return val.(netip.Addr).Next(), nil
+			}
+			return val, nil
+		}
+
+		var (
+			id   int
+			got  netip.Addr
+			dest = []interface{}{&id, &got}
+		)
+		iter := session.Query(`SELECT id, addr FROM codec_hosts`, nil).WithScanTransformer(next).Iter()
+		if !iter.Scan(dest...) {
+			t.Fatal("Scan() failed:", iter.Close())
+		}
+		if err := iter.Close(); err != nil {
+			t.Fatal("Close() failed:", err)
+		}
+		if got != addr.Next() {
+			t.Fatalf("Scan() addr=%s expected %s", got, addr.Next())
+		}
+		if dest[1] != &got {
+			t.Fatal("Scan() modified dest")
+		}
+	})
+}
+
+func TestIterxSelectors(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+
+	if err := session.ExecStmt(`CREATE TABLE gocqlx_test.selectors_table (id int PRIMARY KEY, name text, tags map<text, text>)`); err != nil {
+		t.Fatal("create table:", err)
+	}
+	if err := session.ExecStmt(`INSERT INTO gocqlx_test.selectors_table (id, name, tags) VALUES (1, 'foo', {'a': 'b'}) USING TTL 1000 AND TIMESTAMP 42`); err != nil {
+		t.Fatal("insert:", err)
+	}
+
+	var v struct {
+		Name    string
+		Written int64  `db:"written"`
+		TTL     int    `db:"Expires"`
+		ID      string `db:"id_text"`
+		Tag     string `db:"tag"`
+	}
+	q := qb.Select("gocqlx_test.selectors_table").
+		Columns("name").
+		Selectors(
+			qb.WriteTime("name").As("written"),
+			qb.TTLOf("name").As("Expires"),
+			qb.Cast("id", "text").As("id_text"),
+			qb.ElemLit("tags", "'a'").As("tag"),
+		).
+		Query(session).Strict()
+	if err := q.GetRelease(&v); err != nil {
+		t.Fatal("Get() failed:", err)
+	}
+	if v.Name != "foo" || v.Written != 42 || v.TTL <= 0 || v.TTL > 1000 || v.ID != "1" || v.Tag != "b" {
+		t.Fatalf("Get()=%+v", v)
+	}
+}
+
+func TestIterxExpandIn(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+
+	if err := session.ExecStmt(`CREATE TABLE gocqlx_test.expand_in_table (id int PRIMARY KEY, name text)`); err != nil {
+		t.Fatal("create table:", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := session.Query(`INSERT INTO gocqlx_test.expand_in_table (id, name) VALUES (?, 'foo')`, nil).Bind(i).ExecRelease(); err != nil {
+			t.Fatal("insert:", err)
+		}
+	}
+
+	q := qb.Select("gocqlx_test.expand_in_table").Columns("id").Where(qb.In("id")).Query(session).ExpandIn("id")
+	defer q.Release()
+
+	for _, ids := range [][]int{{1, 2, 3}, {4}, {}} {
+		var got []int
+		if err := q.BindMap(qb.M{"id": ids}).Select(&got); err != nil {
+			t.Fatal("Select() failed:", err)
+		}
+		if diff := cmp.Diff(ids, got, cmpopts.SortSlices(func(a, b int) bool { return a < b }), cmpopts.EquateEmpty()); diff != "" {
+			t.Fatal(q.Statement(), diff)
+		}
+	}
+}
+
+func TestIterxRender(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+
+	if err := session.ExecStmt(`CREATE TYPE gocqlx_test.render_point (x int, y int)`); err != nil {
+		t.Fatal("create type:", err)
+	}
+	if err := session.ExecStmt(`CREATE TABLE gocqlx_test.render_table (
+		id int PRIMARY KEY,
+		name text,
+		data blob,
+		uuid timeuuid,
+		day date,
+		at time,
+		tags set<text>,
+		scores map<text, double>,
+		pair tuple<int, text>,
+		point frozen<render_point>,
+		secret text)`); err != nil {
+		t.Fatal("create table:", err)
+	}
+
+	type Point struct {
+		gocqlx.UDT
+		X int
+		Y int
+	}
+	type Row struct {
+		Name   string
+		Data   []byte
+		UUID   gocql.UUID
+		Day    time.Time
+		At     time.Duration
+		Tags   []string
+		Scores map[string]float64
+		Pair   []interface{}
+		Point  Point
+		Secret string
+		ID     int
+	}
+	row := Row{
+		ID:     1,
+		Name:   "O'Brien",
+		Data:   []byte{0xca, 0xfe},
+		UUID:   gocql.TimeUUID(),
+		Day:    time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		At:     13*time.Hour + 14*time.Minute,
+		Tags:   []string{"b", "a"},
+		Scores: map[string]float64{"x": 1.5},
+		Pair:   []interface{}{1, "foo"},
+		Point:  Point{X: 1, Y: 2},
+		Secret: "secret",
+	}
+
+	columns := []string{"id", "name", "data", "uuid", "day", "at", "tags", "scores", "pair", "point", "secret"}
+
+	// Get types from result metadata
+	iter := qb.Select("gocqlx_test.render_table").Columns(columns...).Query(session).Iter()
+	types := make(map[string]gocql.TypeInfo)
+	for _, c := range iter.Columns() {
+		types[c.Name] = c.TypeInfo
+	}
+	if err := iter.Close(); err != nil {
+		t.Fatal("select:", err)
+	}
+
+	q := qb.Insert("gocqlx_test.render_table").Columns(columns...).Query(session).BindStruct(row)
+	defer q.Release()
+
+	r := gocqlx.Renderer{Types: types}
+	stmt, err := r.RenderQuery(q)
+	if err != nil {
+		t.Fatal("RenderQuery() failed:", err)
+	}
+	if err := session.ExecStmt(strings.Replace(stmt, "(1,", "(2,", 1)); err != nil {
+		t.Fatal(stmt, err)
+	}
+
+	var got Row
+	if err := qb.Select("gocqlx_test.render_table").Where(qb.EqLit("id", "2")).Query(session).GetRelease(&got); err != nil {
+		t.Fatal("get:", err)
+	}
+	row.ID = 2
+	opts := cmpopts.SortSlices(func(a, b string) bool { return a < b })
+	if diff := cmp.Diff(row, got, opts); diff != "" {
+		t.Fatal(stmt, diff)
+	}
+
+	r.Redact = []string{"secret"}
+	stmt, err = r.RenderQuery(q)
+	if err != nil {
+		t.Fatal("RenderQuery() failed:", err)
+	}
+	if strings.Contains(stmt, "'secret'") || !strings.Contains(stmt, gocqlx.RedactedLiteral) {
+		t.Fatal("secret not redacted", stmt)
+	}
+}
+
+func TestIterxDetector(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+
+	if err := session.ExecStmt(`CREATE TABLE gocqlx_test.detector_table (id int, ts int, name text, PRIMARY KEY (id, ts))`); err != nil {
+		t.Fatal("create table:", err)
+	}
+
+	var found []qb.AntiPattern
+	session.Inspector = &qb.Detector{
+		Schema: table.SchemaFunc(session.Session),
+		Report: func(a qb.AntiPattern) {
+			found = append(found, a)
+		},
+		MaxBatchPartitions: 1,
+	}
+
+	insert := qb.Insert("gocqlx_test.detector_table").Columns("id", "ts", "name").Unique()
+	if err := insert.Query(session).Bind(1, 1, "a").Idempotent(true).Exec(); err != nil {
+		t.Fatal("insert:", err)
+	}
+
+	var names []string
+	if err := qb.Select("gocqlx_test.detector_table").Columns("name").Where(qb.Gt("ts")).AllowFiltering().Query(session).Bind(0).Select(&names); err != nil {
+		t.Fatal("select:", err)
+	}
+
+	b := session.Batch(gocql.LoggedBatch)
+	stmt, _ := qb.Insert("gocqlx_test.detector_table").Columns("id", "ts", "name").ToCql()
+	b.Query(stmt, 1, 2, "b")
+	b.Query(stmt, 2, 2, "b")
+	if err := session.ExecuteBatch(b); err != nil {
+		t.Fatal("batch:", err)
+	}
+
+	var kinds []qb.AntiPatternKind
+	for _, a := range found {
+		kinds = append(kinds, a.Kind)
+	}
+	golden := []qb.AntiPatternKind{
+		qb.AntiPatternNonIdempotent,
+		qb.AntiPatternAllowFiltering,
+		qb.AntiPatternUnboundedSelect,
+		qb.AntiPatternMultiPartitionBatch,
+	}
+	if diff := cmp.Diff(golden, kinds); diff != "" {
+		t.Fatal(diff, found)
+	}
+}
@@ -14,4 +14,22 @@ import (
 // use on a type.
 //
 // A custom mapper can always be set per Sessionm, Query and Iter.
+//
+// Besides the column name the `db` tag can hold comma separated options,
+// i.e. `db:"name,omitempty"`, fields tagged `db:"-"` are ignored.
 var DefaultMapper = reflectx.NewMapperFunc("db", reflectx.CamelToSnakeASCII)
+
+// Struct tag options understood by gocqlx.
+const (
+	// OmitEmptyOption binds gocql.UnsetValue instead of the zero value of
+	// the field, so that a partial write does not overwrite the column.
+	// Unlike UnsetEmptyTransformer it applies only to the tagged fields,
+	// other fields are written even if they hold zero values.
+	OmitEmptyOption = "omitempty"
+	// ReadOnlyOption marks a field that is read but never written, i.e.
+	// a column maintained by the database or another service. It is skipped
+	// by statements generated with table.Table.WithModel. It is not checked
+	// when binding, statements of a table without a model and statements
+	// built with qb write the column if they list it.
+	ReadOnlyOption = "readonly"
+)
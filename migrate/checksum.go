@@ -6,31 +6,115 @@ package migrate
 
 import (
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
-	"io"
+	"fmt"
+	"hash"
 	"io/fs"
+	"strings"
 )
 
+// ChecksumAlgorithm specifies how checksum of a migration file is calculated.
+// Checksums are stored in the database prefixed with the algorithm name,
+// i.e. "sha256:<hex>", except for MD5 which is stored without prefix for
+// compatibility with older versions.
+// A file already recorded in the database is always validated with
+// the algorithm it was recorded with.
+type ChecksumAlgorithm string
+
+// Supported checksum algorithms.
+const (
+	// ChecksumMD5 is MD5 of the file contents.
+	ChecksumMD5 ChecksumAlgorithm = "md5"
+	// ChecksumSHA256 is SHA-256 of the file contents.
+	ChecksumSHA256 ChecksumAlgorithm = "sha256"
+	// ChecksumSHA256Normalized is SHA-256 of the file statements with
+	// comments removed and whitespace outside of literals collapsed.
+	// Reformatting a file or changing line endings does not change it.
+	// Callback comments are kept.
+	ChecksumSHA256Normalized ChecksumAlgorithm = "sha256norm"
+)
+
+// DefaultChecksumAlgorithm is used to calculate checksums of migrations that
+// are not yet recorded in the database.
+var DefaultChecksumAlgorithm = ChecksumSHA256
+
 var encode = hex.EncodeToString
 
-func checksum(b []byte) string {
-	v := md5.Sum(b)
-	return encode(v[:])
+// checksumAlgorithm returns algorithm of the stored checksum.
+func checksumAlgorithm(c string) ChecksumAlgorithm {
+	if i := strings.IndexByte(c, ':'); i > 0 {
+		return ChecksumAlgorithm(c[:i])
+	}
+	return ChecksumMD5
+}
+
+func checksum(alg ChecksumAlgorithm, b []byte) (string, error) {
+	var h hash.Hash
+	switch alg {
+	case ChecksumMD5:
+		h = md5.New()
+	case ChecksumSHA256:
+		h = sha256.New()
+	case ChecksumSHA256Normalized:
+		h = sha256.New()
+		n, err := normalize(b)
+		if err != nil {
+			return "", err
+		}
+		b = n
+	default:
+		return "", fmt.Errorf("unsupported checksum algorithm %q", alg)
+	}
+
+	h.Write(b)
+	v := encode(h.Sum(nil))
+	if alg == ChecksumMD5 {
+		return v, nil
+	}
+	return string(alg) + ":" + v, nil
 }
 
-func fileChecksum(f fs.FS, path string) (string, error) {
-	file, err := f.Open(path)
+// normalize returns significant tokens, callback comments and comments
+// containing semicolons separated with a single space. Semicolons in comments
+// between statements are numbered as no-op statements, so editing such
+// a comment must change the checksum.
+func normalize(b []byte) ([]byte, error) {
+	tokens, err := lex(string(b))
 	if err != nil {
-		return "", nil
+		return nil, err
 	}
-	defer func() {
-		_ = file.Close()
-	}()
 
-	h := md5.New()
-	if _, err := io.Copy(h, file); err != nil {
+	var (
+		v   []byte
+		sep bool
+	)
+	for _, t := range tokens {
+		text := t.text
+		switch {
+		case t.kind == tokenSpace:
+			continue
+		case t.kind == tokenComment:
+			text = strings.TrimSpace(text)
+			if name := isCallback(text); name != "" {
+				text = "-- CALL " + name + ";"
+			} else if !strings.Contains(text, ";") {
+				continue
+			}
+		}
+		if sep {
+			v = append(v, ' ')
+		}
+		v = append(v, text...)
+		sep = true
+	}
+	return v, nil
+}
+
+func fileChecksum(f fs.FS, path string, alg ChecksumAlgorithm) (string, error) {
+	b, err := fs.ReadFile(f, path)
+	if err != nil {
 		return "", err
 	}
-	v := h.Sum(nil)
-	return encode(v), nil
+	return checksum(alg, b)
 }
@@ -10,11 +10,70 @@ import (
 )
 
 func TestFileChecksum(t *testing.T) {
-	c, err := fileChecksum(os.DirFS("testdata"), "file")
+	table := []struct {
+		Alg      ChecksumAlgorithm
+		Checksum string
+	}{
+		{
+			Alg:      ChecksumMD5,
+			Checksum: "bbe02f946d5455d74616fc9777557c22",
+		},
+		{
+			Alg:      ChecksumSHA256,
+			Checksum: "sha256:8b911a8716b94442f9ca3dff20584048536e4c2f47b8b5bb9096cbd43c3432d5",
+		},
+	}
+
+	for _, test := range table {
+		c, err := fileChecksum(os.DirFS("testdata"), "file", test.Alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != test.Checksum {
+			t.Fatal(test.Alg, c)
+		}
+		if alg := checksumAlgorithm(c); alg != test.Alg {
+			t.Fatal(test.Alg, alg)
+		}
+	}
+}
+
+func TestChecksumNormalized(t *testing.T) {
+	a := "-- Comment\nCREATE TABLE foo (id int PRIMARY KEY, t text);\n\n-- CALL Foo;\nINSERT INTO foo (id, t) VALUES (1, 'a  b');\n"
+	b := "CREATE TABLE foo(\r\n  id int PRIMARY KEY,\r\n  t text\r\n); /* comment */\r\n--   CALL Foo;\r\nINSERT INTO foo (id, t)\r\nVALUES (1, 'a  b');"
+
+	ca, err := checksum(ChecksumSHA256Normalized, []byte(a))
 	if err != nil {
 		t.Fatal(err)
 	}
-	if c != "bbe02f946d5455d74616fc9777557c22" {
-		t.Fatal(c)
+	cb, err := checksum(ChecksumSHA256Normalized, []byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ca != cb {
+		t.Fatalf("checksum mismatch %s %s", ca, cb)
+	}
+	if alg := checksumAlgorithm(ca); alg != ChecksumSHA256Normalized {
+		t.Fatal(alg)
+	}
+
+	for _, c := range []string{
+		"CREATE TABLE foo (id int PRIMARY KEY, t text);\nINSERT INTO foo (id, t) VALUES (1, 'a  b');",
+		"CREATE TABLE foo (id int PRIMARY KEY, t text);\n-- CALL Foo;\nINSERT INTO foo (id, t) VALUES (1, 'a b');",
+		"-- Comment;\nCREATE TABLE foo (id int PRIMARY KEY, t text);\n-- CALL Foo;\nINSERT INTO foo (id, t) VALUES (1, 'a  b');",
+	} {
+		cc, err := checksum(ChecksumSHA256Normalized, []byte(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cc == ca {
+			t.Fatalf("expected checksum change for %q", c)
+		}
+	}
+}
+
+func TestChecksumUnsupported(t *testing.T) {
+	if _, err := checksum("sha1", nil); err == nil {
+		t.Fatal("expected error")
 	}
 }
@@ -6,4 +6,16 @@
 // There is no imposed naming schema. Migration name is file name.
 // The order of migrations is the lexicographical order of file names in the directory.
 // You can inject execution of Go code before processing of a migration file, after processing of a migration file, or between statements in a migration file.
+//
+// Statements are terminated by semicolons. Semicolons inside string literals, $$ strings,
+// quoted identifiers, comments and BEGIN BATCH ... APPLY BATCH blocks do not end a statement.
+// Both line comments (-- and //) and block comments (/* */) are supported.
+// Comment only and empty statements are not executed but still count as steps
+// of the migration, see Info.Done.
+//
+// Migrations written in Go can be added with Register, they are ordered by name
+// together with the CQL files.
+//
+// Long histories can be squashed into a snapshot file with a `-- +squash <name>` header,
+// see Baseline for adopting migrations on an existing database.
 package migrate
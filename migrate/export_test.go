@@ -4,6 +4,8 @@ func IsCallback(stmt string) (name string) {
 	return isCallback(stmt)
 }
 
-func IsComment(stmt string) bool {
-	return isComment(stmt)
+func Unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, name)
 }
@@ -0,0 +1,258 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LintSeverity specifies severity of a LintIssue.
+type LintSeverity uint8
+
+// enumeration of LintSeverities
+const (
+	// LintError is a problem that fails the migration.
+	LintError LintSeverity = iota
+	// LintWarning is a statement that is risky or unsafe to rerun.
+	LintWarning
+)
+
+func (s LintSeverity) String() string {
+	if s == LintWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// LintIssue is a problem found in a migration by Lint.
+type LintIssue struct {
+	// Migration is the migration name, it's empty for issues concerning
+	// multiple migrations.
+	Migration string
+	Message   string
+	// Line is the line number of the statement, it's 0 for issues
+	// concerning the whole file.
+	Line     int
+	Severity LintSeverity
+}
+
+func (i LintIssue) String() string {
+	if i.Migration == "" {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", i.Migration, i.Line, i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Migration, i.Severity, i.Message)
+}
+
+// Linter checks migrations without connecting to a database.
+type Linter struct {
+	// Migrator specifies how migrations are listed and rendered,
+	// if nil the zero Migrator is used.
+	Migrator *Migrator
+	// Callbacks, if not nil, must have a CallComment handler for every
+	// `-- CALL <name>;` comment.
+	Callbacks CallbackRegister
+}
+
+// Lint checks migrations in f without connecting to a database,
+// see Linter.Lint for details.
+func Lint(f fs.FS) ([]LintIssue, error) {
+	return (&Linter{}).Lint(f)
+}
+
+// Lint checks migrations in f and returns issues ordered by migration and
+// line. It reports as errors files that can't be split into statements,
+// empty files, invalid header directives, invalid squash snapshots and calls
+// to missing callbacks. It reports as warnings comment only files, that are
+// recorded as applied without executing anything, numbered files that do not
+// sort in numeric order, DROP, TRUNCATE and ALTER ... TYPE statements, and
+// CREATE or DROP statements without IF [NOT] EXISTS that fail when rerun.
+// Go code migrations are not checked. The error is returned only if
+// migrations can't be listed.
+func (l *Linter) Lint(f fs.FS) ([]LintIssue, error) {
+	mg := l.Migrator
+	if mg == nil {
+		mg = &Migrator{}
+	}
+	f = mg.renderFS(f)
+
+	fm, err := listMigrations(f, mg.Namespaced)
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+
+	var v []LintIssue
+	report := func(name string, line int, severity LintSeverity, format string, args ...interface{}) {
+		v = append(v, LintIssue{
+			Migration: name,
+			Message:   fmt.Sprintf(format, args...),
+			Line:      line,
+			Severity:  severity,
+		})
+	}
+
+	for _, m := range fm {
+		if m.fn != nil {
+			continue
+		}
+		stmts, err := m.statements(f)
+		if err != nil {
+			report(m.name, 0, LintError, "%s", err)
+			continue
+		}
+		if len(stmts) == 0 {
+			report(m.name, 0, LintError, "no migration statements found")
+			continue
+		}
+		if !slices.ContainsFunc(stmts, func(s statement) bool { return !s.noop }) {
+			report(m.name, 0, LintWarning, "only comments found, file is recorded as applied without executing anything")
+		}
+		b, err := fs.ReadFile(f, m.name)
+		if err != nil {
+			report(m.name, 0, LintError, "%s", err)
+			continue
+		}
+		if _, err := mg.fileOptions(b); err != nil {
+			report(m.name, 0, LintError, "%s", err)
+		}
+		for _, stmt := range stmts {
+			if stmt.noop {
+				continue
+			}
+			if stmt.callback != "" {
+				if l.Callbacks != nil && l.Callbacks.Find(CallComment, stmt.callback) == nil {
+					report(m.name, stmt.line, LintError, "missing handler for callback %q", stmt.callback)
+				}
+				continue
+			}
+			for _, msg := range lintStatement(stmt.tokens) {
+				report(m.name, stmt.line, LintWarning, "%s", msg)
+			}
+		}
+	}
+
+	lintOrder(fm, func(name, format string, args ...interface{}) {
+		report(name, 0, LintWarning, format, args...)
+	})
+
+	// squash snapshots are resolved only if files are readable
+	valid := true
+	for _, i := range v {
+		valid = valid && i.Severity != LintError
+	}
+	if _, err := mg.sequences(f, nil); err != nil && valid {
+		report("", 0, LintError, "%s", err)
+	}
+
+	index := make(map[string]int, len(fm))
+	for i, m := range fm {
+		index[m.name] = i
+	}
+	sort.SliceStable(v, func(i, j int) bool {
+		if v[i].Migration != v[j].Migration {
+			return index[v[i].Migration] < index[v[j].Migration]
+		}
+		return v[i].Line < v[j].Line
+	})
+
+	return v, nil
+}
+
+// lintStatement returns warnings for a CQL statement.
+func lintStatement(tokens []token) []string {
+	var v []string
+
+	word := func(i int) string {
+		if i < len(tokens) && tokens[i].kind == tokenWord {
+			return strings.ToUpper(tokens[i].text)
+		}
+		return ""
+	}
+	has := func(words ...string) bool {
+		return containsWords(tokens, words...)
+	}
+
+	switch word(0) {
+	case "DROP":
+		kind := word(1)
+		if kind == "MATERIALIZED" || kind == "SERVICE" {
+			kind += " " + word(2)
+		}
+		v = append(v, fmt.Sprintf("DROP %s removes data", kind))
+		if !has("IF", "EXISTS") {
+			v = append(v, "DROP without IF EXISTS fails when rerun")
+		}
+	case "TRUNCATE":
+		v = append(v, "TRUNCATE removes data")
+	case "CREATE":
+		if !has("IF", "NOT", "EXISTS") && !has("OR", "REPLACE") {
+			v = append(v, "CREATE without IF NOT EXISTS fails when rerun")
+		}
+	case "ALTER":
+		if word(1) != "TABLE" {
+			break
+		}
+		if has("DROP") {
+			v = append(v, "ALTER TABLE ... DROP removes data")
+		}
+		for i := 2; i < len(tokens); i++ {
+			if word(i) == "ALTER" && word(i+2) == "TYPE" {
+				v = append(v, "ALTER ... TYPE changes how existing data is read")
+				break
+			}
+		}
+	}
+
+	return v
+}
+
+// lintOrder reports migrations with numeric prefixes that do not sort in
+// numeric order, i.e. "10_users.cql" sorting before "9_orders.cql", and
+// migrations sharing a number.
+func lintOrder(fm []migration, report func(name, format string, args ...interface{})) {
+	var (
+		ns      string
+		prev    string
+		prevNum = -1
+	)
+	for _, m := range fm {
+		if namespace(m.name) != ns {
+			ns, prevNum = namespace(m.name), -1
+		}
+		num, ok := migrationNumber(m.name)
+		if !ok {
+			continue
+		}
+		switch {
+		case prevNum < 0:
+		case num < prevNum:
+			report(m.name, "sorts after %q but has a lower number, pad numbers with zeros", prev)
+		case num == prevNum:
+			report(m.name, "has the same number as %q", prev)
+		}
+		prev, prevNum = m.name, num
+	}
+}
+
+// migrationNumber returns the leading number of the migration base name.
+func migrationNumber(name string) (int, bool) {
+	name = name[strings.LastIndexByte(name, '/')+1:]
+	i := 0
+	for i < len(name) && name[i] >= '0' && name[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(name[:i])
+	return n, err == nil
+}
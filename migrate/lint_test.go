@@ -0,0 +1,95 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/psanford/memfs"
+
+	"github.com/scylladb/gocqlx/v3"
+)
+
+func TestLint(t *testing.T) {
+	files := map[string]string{
+		"001_schema.cql": "CREATE TABLE IF NOT EXISTS foo (id int PRIMARY KEY, v text);\n" +
+			"CREATE INDEX foo_v ON foo (v);\n" +
+			"CREATE OR REPLACE FUNCTION f (a int) RETURNS NULL ON NULL INPUT RETURNS int LANGUAGE lua AS 'return a';\n",
+		"002_callback.cql": "-- CALL Registered;\n-- CALL Missing;\n",
+		"003_empty.cql":    "-- nothing here\n",
+		"004_broken.cql":   "INSERT INTO foo (id, v) VALUES (1, 'unterminated);\n",
+		"005_drop.cql": "DROP TABLE IF EXISTS bar;\n" +
+			"DROP MATERIALIZED VIEW IF EXISTS bar_by_v;\n" +
+			"TRUNCATE foo;\n" +
+			"ALTER TABLE foo DROP v;\n" +
+			"ALTER TABLE foo ALTER v TYPE blob;\n" +
+			"ALTER TYPE udt ADD type text;\n" +
+			"INSERT INTO foo (id, v) VALUES (1, 'DROP TABLE foo');\n",
+		"006_timeout.cql": "-- +timeout soon\nCREATE TABLE IF NOT EXISTS bar (id int PRIMARY KEY);\n",
+		"10_late.cql":     "DROP KEYSPACE ks;\n",
+		"9_early.cql":     "SELECT * FROM foo;\n",
+	}
+	f := memfs.New()
+	for name, data := range files {
+		if err := f.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	noop := func(context.Context, gocqlx.Session, CallbackEvent, string) error { return nil }
+	reg := CallbackRegister{}
+	reg.Add(CallComment, "Registered", noop)
+
+	v, err := (&Linter{Callbacks: reg}).Lint(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var issues []string
+	for _, i := range v {
+		issues = append(issues, i.String())
+	}
+
+	golden := []string{
+		`001_schema.cql:2: warning: CREATE without IF NOT EXISTS fails when rerun`,
+		`002_callback.cql:2: error: missing handler for callback "Missing"`,
+		`003_empty.cql: warning: only comments found, file is recorded as applied without executing anything`,
+		`004_broken.cql: error: line 1: unterminated string literal`,
+		`005_drop.cql:1: warning: DROP TABLE removes data`,
+		`005_drop.cql:2: warning: DROP MATERIALIZED VIEW removes data`,
+		`005_drop.cql:3: warning: TRUNCATE removes data`,
+		`005_drop.cql:4: warning: ALTER TABLE ... DROP removes data`,
+		`005_drop.cql:5: warning: ALTER ... TYPE changes how existing data is read`,
+		`006_timeout.cql: error: invalid +timeout directive "soon"`,
+		`10_late.cql:1: warning: DROP KEYSPACE removes data`,
+		`10_late.cql:1: warning: DROP without IF EXISTS fails when rerun`,
+		`9_early.cql: warning: sorts after "10_late.cql" but has a lower number, pad numbers with zeros`,
+	}
+	if diff := cmp.Diff(golden, issues); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestLintSquash(t *testing.T) {
+	f := memfs.New()
+	for _, name := range []string{"1_snapshot.cql", "2_snapshot.cql"} {
+		if err := f.WriteFile(name, []byte("-- +squash 0.cql\nSELECT * FROM foo;"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v, err := Lint(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := []LintIssue{{
+		Message:  `multiple snapshots found, "1_snapshot.cql" and "2_snapshot.cql"`,
+		Severity: LintError,
+	}}
+	if diff := cmp.Diff(golden, v); diff != "" {
+		t.Fatal(diff)
+	}
+}
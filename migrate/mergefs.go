@@ -0,0 +1,120 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MergeFS returns a file system containing every file system from dirs
+// as a subdirectory named by the map key, the key must not contain slashes.
+// It can be used with Migrator.Namespaced to apply migrations of multiple
+// modules, each embedding its own files.
+func MergeFS(dirs map[string]fs.FS) fs.FS {
+	return mergeFS(dirs)
+}
+
+type mergeFS map[string]fs.FS
+
+var _ fs.ReadDirFS = mergeFS(nil)
+
+func (m mergeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &mergeDir{entries: m.entries()}, nil
+	}
+	f, rest, err := m.sub(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return f.Open(rest)
+}
+
+func (m mergeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return m.entries(), nil
+	}
+	f, rest, err := m.sub(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return fs.ReadDir(f, rest)
+}
+
+func (m mergeFS) sub(name string) (f fs.FS, rest string, err error) {
+	dir, rest, _ := strings.Cut(name, "/")
+	f, ok := m[dir]
+	if !ok {
+		return nil, "", fs.ErrNotExist
+	}
+	if rest == "" {
+		rest = "."
+	}
+	return f, rest, nil
+}
+
+func (m mergeFS) entries() []fs.DirEntry {
+	v := make([]fs.DirEntry, 0, len(m))
+	for name := range m {
+		v = append(v, dirInfo(name))
+	}
+	sort.Slice(v, func(i, j int) bool {
+		return v[i].Name() < v[j].Name()
+	})
+	return v
+}
+
+// mergeDir is the root directory of mergeFS.
+type mergeDir struct {
+	entries []fs.DirEntry
+}
+
+func (d *mergeDir) Stat() (fs.FileInfo, error) {
+	return dirInfo("."), nil
+}
+
+func (d *mergeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: fs.ErrInvalid}
+}
+
+func (d *mergeDir) Close() error {
+	return nil
+}
+
+func (d *mergeDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		v := d.entries
+		d.entries = nil
+		return v, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	v := d.entries[:n]
+	d.entries = d.entries[n:]
+	return v, nil
+}
+
+// dirInfo describes a directory of mergeFS.
+type dirInfo string
+
+func (d dirInfo) Name() string               { return string(d) }
+func (d dirInfo) Size() int64                { return 0 }
+func (d dirInfo) Mode() fs.FileMode          { return fs.ModeDir | 0o555 }
+func (d dirInfo) ModTime() time.Time         { return time.Time{} }
+func (d dirInfo) IsDir() bool                { return true }
+func (d dirInfo) Sys() interface{}           { return nil }
+func (d dirInfo) Type() fs.FileMode          { return fs.ModeDir }
+func (d dirInfo) Info() (fs.FileInfo, error) { return d, nil }
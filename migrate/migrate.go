@@ -5,16 +5,18 @@
 package migrate
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
-	"path/filepath"
 	"regexp"
+	"runtime/debug"
 	"sort"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -43,17 +45,33 @@ func (as awaitSchemaAgreement) ShouldAwait(stage awaitSchemaAgreement) bool {
 	return as == stage
 }
 
-const (
-	infoSchema = `CREATE TABLE IF NOT EXISTS gocqlx_migrate (
+// DefaultInfoTable is the name of the table recording applied migrations.
+const DefaultInfoTable = "gocqlx_migrate"
+
+const infoSchema = `CREATE TABLE IF NOT EXISTS %s (
 	name text,
 	checksum text,
 	done int,
 	start_time timestamp,
 	end_time timestamp,
+	applied_by text,
+	duration duration,
+	error text,
+	version text,
 	PRIMARY KEY(name)
 )`
-	selectInfo = "SELECT * FROM gocqlx_migrate"
-)
+
+// infoUpgrade lists columns added to the info table after its first version,
+// tables created by older versions are altered to add them.
+var infoUpgrade = []struct {
+	name string
+	typ  string
+}{
+	{"applied_by", "text"},
+	{"duration", "duration"},
+	{"error", "text"},
+	{"version", "text"},
+}
 
 // Info contains information on migration applied on a database.
 type Info struct {
@@ -61,19 +79,32 @@ type Info struct {
 	EndTime   time.Time
 	Name      string
 	Checksum  string
-	Done      int
+	// AppliedBy is the host name of the machine that applied the migration.
+	AppliedBy string
+	// Error is the error of the last failed attempt to apply a partially
+	// applied migration, it's cleared when the migration progresses.
+	Error string
+	// Version is the gocqlx version that applied the migration.
+	Version string
+	// Duration is the time it took to apply the migration, for a resumed
+	// migration it's the time since it was resumed.
+	Duration time.Duration
+	Done     int
 }
 
 // List provides a listing of applied migrations.
 func List(ctx context.Context, session gocqlx.Session) ([]*Info, error) {
-	if err := ensureInfoTable(ctx, session); err != nil {
-		return nil, err
-	}
+	return (&Migrator{}).List(ctx, session)
+}
 
-	q := session.ContextQuery(ctx, selectInfo, nil)
+// List provides a listing of applied migrations. It does not modify
+// the database, if the table recording applied migrations does not exist
+// the listing is empty.
+func (mg *Migrator) List(ctx context.Context, session gocqlx.Session) ([]*Info, error) {
+	q := session.ContextQuery(ctx, "SELECT * FROM "+mg.infoTable(), nil)
 
 	var v []*Info
-	if err := q.SelectRelease(&v); err == gocql.ErrNotFound {
+	if err := q.SelectRelease(&v); err == gocql.ErrNotFound || isMissingTable(err) {
 		return nil, nil
 	} else if err != nil {
 		return v, err
@@ -86,9 +117,70 @@ func List(ctx context.Context, session gocqlx.Session) ([]*Info, error) {
 	return v, nil
 }
 
+// Migrator applies migrations with custom options. The zero value applies
+// migrations the same way as the package level functions.
+type Migrator struct {
+	// Observer receives events on migration progress, see Observer.
+	Observer Observer
+	// OutOfOrder enables applying migrations that sort before already
+	// applied migrations, i.e. when two feature branches each add a migration.
+	// Applied migrations still have to be present and unchanged.
+	OutOfOrder bool
+	// Namespaced enables migrations in subdirectories, every subdirectory is
+	// an independent sequence of migrations recorded as "<dir>/<name>".
+	// Files in the root directory are applied first, followed by
+	// subdirectories in lexicographical order. Go code migrations are put
+	// into a subdirectory with a "<dir>/" name prefix.
+	// See MergeFS for combining multiple file systems into subdirectories.
+	Namespaced bool
+	// TemplateData enables rendering migration files with text/template,
+	// files are rendered with TemplateData before they are split into
+	// statements and checksummed. The recorded checksum is the checksum of
+	// the rendered file, so changing the data of an applied file fails the
+	// migration. Referencing a missing map key is an error.
+	TemplateData interface{}
+	// TemplateFuncs are functions available in migration templates.
+	TemplateFuncs template.FuncMap
+	// Keyspace of the table recording applied migrations, if empty
+	// the session keyspace is used. Setting it keeps the records in one place
+	// when migrations create or switch keyspaces.
+	Keyspace string
+	// Table recording applied migrations, if empty DefaultInfoTable is used.
+	// The table is created if it does not exist, and tables created by older
+	// versions are altered to add missing columns. It's done once per Migrator
+	// by the first method that records migrations.
+	Table string
+	// StatementTimeout limits execution time of a single CQL statement,
+	// if zero statements run until the session gives up.
+	// A file can override it with a `-- +timeout <duration>` header, i.e.
+	// `-- +timeout 10m` for a long running index build.
+	StatementTimeout time.Duration
+	// SchemaAgreementTimeout limits schema agreement waits, if zero
+	// the session's MaxWaitSchemaAgreement applies.
+	// A file can override it with a `-- +schema-agreement-timeout <duration>` header.
+	SchemaAgreementTimeout time.Duration
+	// Retries is the number of times an idempotent statement, i.e.
+	// CREATE ... IF NOT EXISTS or DROP ... IF EXISTS, is retried after
+	// a timeout or unavailability error. Other statements are not retried.
+	// A file can override it with a `-- +retries <n>` header.
+	Retries int
+
+	// infoMu guards infoReady, that is set once the table recording applied
+	// migrations is created and upgraded.
+	infoMu    sync.Mutex
+	infoReady bool
+}
+
 // Pending provides a listing of pending migrations.
 func Pending(ctx context.Context, session gocqlx.Session, f fs.FS) ([]*Info, error) {
-	applied, err := List(ctx, session)
+	return (&Migrator{}).Pending(ctx, session, f)
+}
+
+// Pending provides a listing of pending migrations.
+func (mg *Migrator) Pending(ctx context.Context, session gocqlx.Session, f fs.FS) ([]*Info, error) {
+	f = mg.renderFS(f)
+
+	applied, err := mg.List(ctx, session)
 	if err != nil {
 		return nil, err
 	}
@@ -99,37 +191,107 @@ func Pending(ctx context.Context, session gocqlx.Session, f fs.FS) ([]*Info, err
 		appliedNames[migration.Name] = struct{}{}
 	}
 
-	fm, err := fs.Glob(f, "*.cql")
+	seqs, err := mg.sequences(f, applied)
 	if err != nil {
-		return nil, fmt.Errorf("list migrations: %w", err)
+		return nil, err
 	}
 
 	pending := make([]*Info, 0)
 
-	for _, name := range fm {
-		baseName := filepath.Base(name)
-		// Check if the migration is not in the applied set
-		if _, exists := appliedNames[baseName]; !exists {
-			c, err := fileChecksum(f, name)
-			if err != nil {
-				return nil, fmt.Errorf("calculate checksum for %q: %w", name, err)
-			}
-
-			info := &Info{
-				Name:      baseName,
-				StartTime: time.Now(),
-				Checksum:  c,
+	for _, seq := range seqs {
+		for _, m := range seq.fm {
+			// Check if the migration is not in the applied set
+			if _, exists := appliedNames[m.name]; !exists {
+				c, err := m.checksum(f, DefaultChecksumAlgorithm)
+				if err != nil {
+					return nil, fmt.Errorf("calculate checksum for %q: %w", m.name, err)
+				}
+
+				info := &Info{
+					Name:      m.name,
+					StartTime: time.Now(),
+					Checksum:  c,
+				}
+
+				pending = append(pending, info)
 			}
-
-			pending = append(pending, info)
 		}
 	}
 
 	return pending, nil
 }
 
-func ensureInfoTable(ctx context.Context, session gocqlx.Session) error {
-	return session.ContextQuery(ctx, infoSchema, nil).ExecRelease()
+// infoTable returns the optionally keyspace qualified name of the table
+// recording applied migrations.
+func (mg *Migrator) infoTable() string {
+	t := mg.Table
+	if t == "" {
+		t = DefaultInfoTable
+	}
+	if mg.Keyspace != "" {
+		t = mg.Keyspace + "." + t
+	}
+	return t
+}
+
+// ensureInfoTable creates the table recording applied migrations and
+// upgrades a table created by an older version. It succeeds once per
+// Migrator, a failed attempt is repeated on the next call.
+func (mg *Migrator) ensureInfoTable(ctx context.Context, session gocqlx.Session) error {
+	mg.infoMu.Lock()
+	defer mg.infoMu.Unlock()
+
+	if mg.infoReady {
+		return nil
+	}
+	if err := mg.createInfoTable(ctx, session); err != nil {
+		return err
+	}
+	mg.infoReady = true
+	return nil
+}
+
+func (mg *Migrator) createInfoTable(ctx context.Context, session gocqlx.Session) error {
+	t := mg.infoTable()
+	if err := session.ContextQuery(ctx, fmt.Sprintf(infoSchema, t), nil).ExecRelease(); err != nil {
+		return err
+	}
+
+	// upgrade table created by an older version
+	iter := session.ContextQuery(ctx, "SELECT * FROM "+t+" LIMIT 1", nil).Iter()
+	columns := make(map[string]struct{})
+	for _, c := range iter.Columns() {
+		columns[c.Name] = struct{}{}
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+
+	altered := false
+	for _, c := range infoUpgrade {
+		if _, ok := columns[c.name]; ok {
+			continue
+		}
+		if err := session.ContextQuery(ctx, "ALTER TABLE "+t+" ADD "+c.name+" "+c.typ, nil).ExecRelease(); err != nil {
+			return fmt.Errorf("upgrade %s: %w", t, err)
+		}
+		altered = true
+	}
+	if altered {
+		return session.AwaitSchemaAgreement(ctx)
+	}
+	return nil
+}
+
+// isMissingTable reports whether err is caused by a table or keyspace that
+// does not exist.
+func isMissingTable(err error) bool {
+	var re gocql.RequestError
+	if !errors.As(err, &re) || re.Code() != gocql.ErrCodeInvalid {
+		return false
+	}
+	msg := strings.ToLower(re.Message())
+	return strings.Contains(msg, "unconfigured table") || strings.Contains(msg, "does not exist")
 }
 
 // Migrate is a wrapper around FromFS.
@@ -150,67 +312,167 @@ func Migrate(ctx context.Context, session gocqlx.Session, dir string) error {
 //
 // It supports code based migrations, see Callback and CallbackFunc.
 // Any comment in form `-- CALL <name>;` will trigger an CallComment callback.
+// Go code migrations added with Register run in order together with the files.
 func FromFS(ctx context.Context, session gocqlx.Session, f fs.FS) error {
+	return (&Migrator{}).FromFS(ctx, session, f)
+}
+
+// FromFS executes new CQL files from a file system abstraction (io/fs.FS),
+// see the FromFS function for details.
+func (mg *Migrator) FromFS(ctx context.Context, session gocqlx.Session, f fs.FS) error {
+	f = mg.renderFS(f)
+
+	if err := mg.ensureInfoTable(ctx, session); err != nil {
+		return err
+	}
+
 	// get database migrations
-	dbm, err := List(ctx, session)
+	dbm, err := mg.List(ctx, session)
 	if err != nil {
 		return fmt.Errorf("list migrations: %w", err)
 	}
 
-	// get file migrations
-	fm, err := fs.Glob(f, "*.cql")
+	// get file and Go code migrations grouped by namespace
+	seqs, err := mg.sequences(f, dbm)
 	if err != nil {
-		return fmt.Errorf("list migrations: %w", err)
+		return err
+	}
+	found := false
+	for _, seq := range seqs {
+		found = found || len(seq.fm) > 0 || len(seq.skipped) > 0
 	}
-	if len(fm) == 0 {
+	if !found {
 		return fmt.Errorf("no migration files found")
 	}
-	sort.Strings(fm)
+	for _, seq := range seqs {
+		if len(seq.fm) == 0 && len(seq.dbm) > 0 {
+			return fmt.Errorf("database is ahead, no migrations found for %q", seq.dbm[0].Name)
+		}
+	}
+
+	for _, seq := range seqs {
+		if mg.OutOfOrder {
+			err = mg.applyOutOfOrder(ctx, session, f, seq.dbm, seq.fm)
+		} else {
+			err = mg.applyInOrder(ctx, session, f, seq.dbm, seq.fm)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = mg.awaitSchemaAgreement(ctx, session, "", mg.SchemaAgreementTimeout); err != nil {
+		return fmt.Errorf("awaiting schema agreement: %w", err)
+	}
+
+	return nil
+}
 
+// applyInOrder requires database migrations to be a prefix of the migrations,
+// it resumes the last database migration and applies the remaining ones.
+func (mg *Migrator) applyInOrder(ctx context.Context, session gocqlx.Session, f fs.FS, dbm []*Info, fm []migration) error {
 	// verify migrations
 	if len(dbm) > len(fm) {
 		return fmt.Errorf("database is ahead")
 	}
 
 	for i := 0; i < len(dbm); i++ {
-		if dbm[i].Name != fm[i] {
-			return fmt.Errorf("inconsistent migrations found, expected %q got %q at %d", dbm[i].Name, fm[i], i)
-		}
-		c, err := fileChecksum(f, fm[i])
-		if err != nil {
-			return fmt.Errorf("calculate checksum for %q: %s", fm[i], err)
+		if dbm[i].Name != fm[i].name {
+			return fmt.Errorf("inconsistent migrations found, expected %q got %q at %d", dbm[i].Name, fm[i].name, i)
 		}
-		if dbm[i].Checksum != c {
-			return fmt.Errorf("file %q was tampered with, expected md5 %s", fm[i], dbm[i].Checksum)
+		if err := verifyChecksum(f, dbm[i], fm[i]); err != nil {
+			return err
 		}
 	}
 
 	// apply migrations
 	if len(dbm) > 0 {
 		last := len(dbm) - 1
-		if err := applyMigration(ctx, session, f, fm[last], dbm[last].Done); err != nil {
-			return fmt.Errorf("apply migration %q: %w", fm[last], err)
+		alg := checksumAlgorithm(dbm[last].Checksum)
+		if err := mg.apply(ctx, session, f, fm[last], dbm[last].Done, alg); err != nil {
+			return fmt.Errorf("apply migration %q: %w", fm[last].name, err)
 		}
 	}
 
 	for i := len(dbm); i < len(fm); i++ {
-		if err := applyMigration(ctx, session, f, fm[i], 0); err != nil {
-			return fmt.Errorf("apply migration %q: %w", fm[i], err)
+		if err := mg.apply(ctx, session, f, fm[i], 0, DefaultChecksumAlgorithm); err != nil {
+			return fmt.Errorf("apply migration %q: %w", fm[i].name, err)
 		}
 	}
 
-	if err = session.AwaitSchemaAgreement(ctx); err != nil {
-		return fmt.Errorf("awaiting schema agreement: %w", err)
+	return nil
+}
+
+// applyOutOfOrder requires every database migration to be present, it resumes
+// partially applied migrations and applies the remaining ones in order.
+func (mg *Migrator) applyOutOfOrder(ctx context.Context, session gocqlx.Session, f fs.FS, dbm []*Info, fm []migration) error {
+	applied := make(map[string]*Info, len(dbm))
+	for _, info := range dbm {
+		applied[info.Name] = info
+	}
+
+	// verify migrations
+	found := 0
+	for _, m := range fm {
+		info, ok := applied[m.name]
+		if !ok {
+			continue
+		}
+		if err := verifyChecksum(f, info, m); err != nil {
+			return err
+		}
+		found++
 	}
+	if found != len(dbm) {
+		return fmt.Errorf("database is ahead")
+	}
+
+	// apply migrations
+	for _, m := range fm {
+		done, alg := 0, DefaultChecksumAlgorithm
+		if info, ok := applied[m.name]; ok {
+			n, err := m.steps(f)
+			if err != nil {
+				return fmt.Errorf("read %q: %w", m.name, err)
+			}
+			if info.Done >= n {
+				continue
+			}
+			done, alg = info.Done, checksumAlgorithm(info.Checksum)
+		}
+		if err := mg.apply(ctx, session, f, m, done, alg); err != nil {
+			return fmt.Errorf("apply migration %q: %w", m.name, err)
+		}
+	}
+
+	return nil
+}
 
+func verifyChecksum(f fs.FS, info *Info, m migration) error {
+	c, err := m.checksum(f, checksumAlgorithm(info.Checksum))
+	if err != nil {
+		return fmt.Errorf("calculate checksum for %q: %s", m.name, err)
+	}
+	if info.Checksum != c {
+		return fmt.Errorf("file %q was tampered with, expected checksum %s got %s", m.name, info.Checksum, c)
+	}
 	return nil
 }
 
+func (mg *Migrator) apply(ctx context.Context, session gocqlx.Session, f fs.FS, m migration, done int, alg ChecksumAlgorithm) error {
+	if m.fn != nil {
+		return mg.applyFunc(ctx, session, m.name, m.fn, done)
+	}
+	return mg.applyMigration(ctx, session, f, m.name, done, alg)
+}
+
 // applyMigration executes a single migration file by parsing and applying its statements.
-// It handles three types of content in migration files:
-//   - SQL statements: executed against the database
+// The file is split with splitStatements, which yields two types of statements:
+//   - CQL statements: executed against the database
 //   - Callback commands: processed via registered callback handlers (format: -- CALL function_name;)
-//   - Regular comments: silently skipped (format: -- any comment text)
+//
+// Regular comments are skipped, comment only and empty statements are no-ops
+// that only advance Info.Done.
 //
 // The function maintains migration state by tracking the number of completed statements,
 // allowing for resumption of partially completed migrations.
@@ -222,9 +484,10 @@ func FromFS(ctx context.Context, session gocqlx.Session, f fs.FS) error {
 //   - f: filesystem containing the migration file
 //   - path: path to the migration file within the filesystem
 //   - done: number of statements already completed (for resuming partial migrations)
+//   - alg: checksum algorithm to record the file checksum with
 //
 // Returns an error if the migration fails at any point.
-func applyMigration(ctx context.Context, session gocqlx.Session, f fs.FS, path string, done int) error {
+func (mg *Migrator) applyMigration(ctx context.Context, session gocqlx.Session, f fs.FS, path string, done int, alg ChecksumAlgorithm) (err error) {
 	file, err := f.Open(path)
 	if err != nil {
 		return err
@@ -236,49 +499,65 @@ func applyMigration(ctx context.Context, session gocqlx.Session, f fs.FS, path s
 		return err
 	}
 
-	info := Info{
-		Name:      filepath.Base(path),
-		StartTime: time.Now(),
-		Checksum:  checksum(b),
+	c, err := checksum(alg, b)
+	if err != nil {
+		return err
 	}
 
-	stmt, names := qb.Insert("gocqlx_migrate").Columns(
-		"name",
-		"checksum",
-		"done",
-		"start_time",
-		"end_time",
-	).ToCql()
+	info := newInfo(path, c)
+
+	stmts, err := splitStatements(b)
+	if err != nil {
+		return err
+	}
+	if len(stmts) == 0 {
+		return fmt.Errorf("no migration statements found in %q", info.Name)
+	}
+	if len(stmts) <= done {
+		return nil
+	}
+	opts, err := mg.fileOptions(b)
+	if err != nil {
+		return err
+	}
+
+	if done > 0 {
+		mg.observe(ctx, Event{Type: MigrationResumed, Migration: info.Name, Index: done})
+	} else {
+		mg.observe(ctx, Event{Type: MigrationStarted, Migration: info.Name})
+	}
+	defer func() {
+		mg.observe(ctx, Event{Type: MigrationFinished, Migration: info.Name, Duration: time.Since(info.StartTime), Err: err})
+	}()
 
 	// Once a statement starts, allow both the statement and its progress update
 	// to finish. The parent context is checked between statements below.
 	operationCtx := context.WithoutCancel(ctx)
-	update := session.ContextQuery(operationCtx, stmt, names)
+	update := mg.updateInfoQuery(operationCtx, session)
 	defer update.Release()
 
+	// record error of a partially applied migration, the record is not
+	// created for a migration that failed before any statement was applied
+	info.Done = done
+	defer func() {
+		if err == nil || info.Done == 0 {
+			return
+		}
+		info.Error = err.Error()
+		info.finish()
+		if uerr := update.BindStruct(info).Exec(); uerr != nil {
+			err = fmt.Errorf("%w, record error: %s", err, uerr)
+		}
+	}()
+
 	if DefaultAwaitSchemaAgreement.ShouldAwait(AwaitSchemaAgreementBeforeEachFile) {
-		if err = session.AwaitSchemaAgreement(ctx); err != nil {
+		if err = mg.awaitSchemaAgreement(ctx, session, info.Name, opts.schemaAgreementTimeout); err != nil {
 			return fmt.Errorf("awaiting schema agreement: %w", err)
 		}
 	}
 
-	i := 0
-	r := bytes.NewBuffer(b)
-	for {
-		stmt, err := r.ReadString(';')
-		if err == io.EOF {
-			if strings.TrimSpace(stmt) != "" {
-				// handle missing semicolon after last statement
-				err = nil
-			} else {
-				break
-			}
-		}
-		if err != nil {
-			return err
-		}
-		i++
-
+	for n, stmt := range stmts {
+		i := n + 1
 		if i <= done {
 			continue
 		}
@@ -297,7 +576,7 @@ func applyMigration(ctx context.Context, session gocqlx.Session, f fs.FS, path s
 		}
 
 		if DefaultAwaitSchemaAgreement.ShouldAwait(AwaitSchemaAgreementBeforeEachStatement) {
-			if err = session.AwaitSchemaAgreement(ctx); err != nil {
+			if err = mg.awaitSchemaAgreement(ctx, session, info.Name, opts.schemaAgreementTimeout); err != nil {
 				return fmt.Errorf("awaiting schema agreement before statement %d: %w", i, err)
 			}
 			if err := ctx.Err(); err != nil {
@@ -305,39 +584,43 @@ func applyMigration(ctx context.Context, session gocqlx.Session, f fs.FS, path s
 			}
 		}
 
-		// trim new lines and all whitespace characters
-		stmt = strings.TrimSpace(stmt)
-
 		// Process statement based on its type
-		if cb := isCallback(stmt); cb != "" {
+		ev := Event{
+			Migration: info.Name,
+			Index:     i,
+			Line:      stmt.line,
+		}
+		if stmt.callback != "" {
 			// Handle callback commands (e.g., "-- CALL function_name;")
 			if Callback == nil {
-				return fmt.Errorf("statement %d: missing callback handler while trying to call %s", i, cb)
+				return fmt.Errorf("statement %d at line %d: missing callback handler while trying to call %s", i, stmt.line, stmt.callback)
 			}
-			if err := Callback(operationCtx, session, CallComment, cb); err != nil {
-				return fmt.Errorf("callback %s: %w", cb, err)
+			ev.Statement = stmt.callback
+			err := mg.observeStep(operationCtx, ev, CallbackStarted, CallbackFinished, func() error {
+				return Callback(operationCtx, session, CallComment, stmt.callback)
+			})
+			if err != nil {
+				return fmt.Errorf("callback %s at line %d: %w", stmt.callback, stmt.line, err)
 			}
-		} else if stmt != "" && !isComment(stmt) {
-			// Execute SQL statements (skip empty statements and comments)
-			q := session.ContextQuery(operationCtx, stmt, nil).RetryPolicy(nil)
-			if err := q.ExecRelease(); err != nil {
-				return fmt.Errorf("statement %d: %w", i, err)
+		} else if !stmt.noop {
+			ev.Statement = stmt.text
+			err := mg.observeStep(operationCtx, ev, StatementStarted, StatementFinished, func() error {
+				return execStatement(operationCtx, session, stmt, opts)
+			})
+			if err != nil {
+				return fmt.Errorf("statement %d at line %d: %w", i, stmt.line, err)
 			}
 		}
-		// Regular comments and empty statements are silently skipped
 
 		// update info
 		info.Done = i
-		info.EndTime = time.Now()
+		info.finish()
 		if err := update.BindStruct(info).Exec(); err != nil {
 			return fmt.Errorf("migration statement %d: %w", i, err)
 		}
 	}
-	if i == 0 {
-		return fmt.Errorf("no migration statements found in %q", info.Name)
-	}
 
-	if Callback != nil && i > done {
+	if Callback != nil {
 		if err := Callback(ctx, session, AfterMigration, info.Name); err != nil {
 			return fmt.Errorf("after migration callback: %w", err)
 		}
@@ -346,6 +629,110 @@ func applyMigration(ctx context.Context, session gocqlx.Session, f fs.FS, path s
 	return nil
 }
 
+// applyFunc executes a Go code migration unless it's already done.
+func (mg *Migrator) applyFunc(ctx context.Context, session gocqlx.Session, name string, fn MigrationFunc, done int) (err error) {
+	if done > 0 {
+		return nil
+	}
+
+	info := newInfo(name, funcChecksum)
+
+	mg.observe(ctx, Event{Type: MigrationStarted, Migration: name})
+	defer func() {
+		mg.observe(ctx, Event{Type: MigrationFinished, Migration: name, Duration: time.Since(info.StartTime), Err: err})
+	}()
+
+	if DefaultAwaitSchemaAgreement != AwaitSchemaAgreementDisabled {
+		if err := mg.awaitSchemaAgreement(ctx, session, name, mg.SchemaAgreementTimeout); err != nil {
+			return fmt.Errorf("awaiting schema agreement: %w", err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context ended before migration: %w", err)
+	}
+
+	if Callback != nil {
+		if err := Callback(ctx, session, BeforeMigration, name); err != nil {
+			return fmt.Errorf("before migration callback: %w", err)
+		}
+	}
+
+	if err := fn(ctx, session); err != nil {
+		return err
+	}
+
+	info.Done = 1
+	info.finish()
+	if err := mg.updateInfoQuery(context.WithoutCancel(ctx), session).BindStruct(info).ExecRelease(); err != nil {
+		return fmt.Errorf("migration: %w", err)
+	}
+
+	if Callback != nil {
+		if err := Callback(ctx, session, AfterMigration, name); err != nil {
+			return fmt.Errorf("after migration callback: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (mg *Migrator) updateInfoQuery(ctx context.Context, session gocqlx.Session) *gocqlx.Queryx {
+	stmt, names := qb.Insert(mg.infoTable()).Columns(
+		"name",
+		"checksum",
+		"done",
+		"start_time",
+		"end_time",
+		"applied_by",
+		"duration",
+		"error",
+		"version",
+	).ToCql()
+
+	return session.ContextQuery(ctx, stmt, names)
+}
+
+// newInfo returns Info of a migration started now.
+func newInfo(name, checksum string) Info {
+	return Info{
+		Name:      name,
+		StartTime: time.Now(),
+		Checksum:  checksum,
+		AppliedBy: hostname(),
+		Version:   version(),
+	}
+}
+
+// finish sets the end time and duration of the migration.
+func (info *Info) finish() {
+	info.EndTime = time.Now()
+	info.Duration = info.EndTime.Sub(info.StartTime)
+}
+
+var hostname = sync.OnceValue(func() string {
+	h, _ := os.Hostname()
+	return h
+})
+
+// version returns version of the gocqlx module the binary is built with.
+var version = sync.OnceValue(func() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if bi.Main.Path == modulePath {
+		return bi.Main.Version
+	}
+	for _, m := range bi.Deps {
+		if m.Path == modulePath {
+			return m.Version
+		}
+	}
+	return ""
+})
+
+const modulePath = "github.com/scylladb/gocqlx/v3"
+
 var cbRegexp = regexp.MustCompile("^-- *CALL +(.+);$")
 
 func isCallback(stmt string) (name string) {
@@ -355,10 +742,3 @@ func isCallback(stmt string) (name string) {
 	}
 	return s[1]
 }
-
-// isComment returns true if the statement is a SQL comment that should be ignored.
-// It distinguishes between regular comments (which should be skipped) and
-// callback commands (which should be processed).
-func isComment(stmt string) bool {
-	return strings.HasPrefix(stmt, "--") && isCallback(stmt) == ""
-}
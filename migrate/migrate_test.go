@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/gocql/gocql"
+	"github.com/google/go-cmp/cmp"
 	"github.com/psanford/memfs"
 
 	"github.com/scylladb/gocqlx/v3"
@@ -143,6 +144,415 @@ func TestMigration(t *testing.T) {
 	})
 }
 
+func TestMigrationChecksum(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	defer func() {
+		migrate.DefaultChecksumAlgorithm = migrate.ChecksumSHA256
+	}()
+
+	ctx := context.Background()
+
+	t.Run("md5", func(t *testing.T) {
+		migrate.DefaultChecksumAlgorithm = migrate.ChecksumMD5
+		if err := migrate.FromFS(ctx, session, makeTestFS(t, 1)); err != nil {
+			t.Fatal(err)
+		}
+		if c := migrationChecksum(t, session, "0.cql"); strings.Contains(c, ":") {
+			t.Fatal("expected md5 checksum got", c)
+		}
+	})
+
+	t.Run("sha256", func(t *testing.T) {
+		migrate.DefaultChecksumAlgorithm = migrate.ChecksumSHA256
+		if err := migrate.FromFS(ctx, session, makeTestFS(t, 2)); err != nil {
+			t.Fatal(err)
+		}
+		if c := migrationChecksum(t, session, "0.cql"); strings.Contains(c, ":") {
+			t.Fatal("expected md5 checksum got", c)
+		}
+		if c := migrationChecksum(t, session, "1.cql"); !strings.HasPrefix(c, "sha256:") {
+			t.Fatal("expected sha256 checksum got", c)
+		}
+	})
+
+	t.Run("normalized", func(t *testing.T) {
+		migrate.DefaultChecksumAlgorithm = migrate.ChecksumSHA256Normalized
+		f := makeTestFS(t, 3)
+		if err := migrate.FromFS(ctx, session, f); err != nil {
+			t.Fatal(err)
+		}
+		if c := migrationChecksum(t, session, "2.cql"); !strings.HasPrefix(c, "sha256norm:") {
+			t.Fatal("expected normalized checksum got", c)
+		}
+
+		writeFile(t, f, 2, "-- reformatted\r\n"+strings.ReplaceAll(fmt.Sprintf(insertMigrate, 2), " ", "\r\n  ")+";\r\n")
+		if err := migrate.FromFS(ctx, session, f); err != nil {
+			t.Fatal(err)
+		}
+
+		writeFile(t, f, 1, "-- reformatted\n"+fmt.Sprintf(insertMigrate, 1)+";")
+		if err := migrate.FromFS(ctx, session, f); err == nil || !strings.Contains(err.Error(), "tampered") {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestMigrationOutOfOrder(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	ctx := context.Background()
+
+	f := memfs.New()
+	for _, i := range []int{0, 2, 3} {
+		writeFile(t, f, i, fmt.Sprintf(insertMigrate, i)+";")
+	}
+	if err := migrate.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, f, 1, fmt.Sprintf(insertMigrate, 1)+";")
+	if err := migrate.FromFS(ctx, session, f); err == nil || !strings.Contains(err.Error(), "inconsistent") {
+		t.Fatal("expected error")
+	}
+
+	m := migrate.Migrator{OutOfOrder: true}
+	pending, err := m.Pending(ctx, session, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Name != "1.cql" {
+		t.Fatalf("unexpected pending migrations %+v", pending)
+	}
+	if err := m.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+	if c := countMigrations(t, session); c != 4 {
+		t.Fatal("expected 4 migration got", c)
+	}
+	if err := m.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+
+	f = memfs.New()
+	for _, i := range []int{0, 1, 3} {
+		writeFile(t, f, i, fmt.Sprintf(insertMigrate, i)+";")
+	}
+	if err := m.FromFS(ctx, session, f); err == nil || !strings.Contains(err.Error(), "ahead") {
+		t.Fatal("expected error")
+	}
+}
+
+func TestMigrationNamespaced(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	ctx := context.Background()
+
+	users := memfs.New()
+	billing := memfs.New()
+	for i, f := range []*memfs.FS{users, billing} {
+		writeFile(t, f, 0, fmt.Sprintf(insertMigrate, 10*i)+";")
+	}
+	f := migrate.MergeFS(map[string]fs.FS{
+		"users":   users,
+		"billing": billing,
+	})
+
+	m := migrate.Migrator{Namespaced: true}
+	if err := m.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, f := range []*memfs.FS{users, billing} {
+		writeFile(t, f, 1, fmt.Sprintf(insertMigrate, 10*i+1)+";")
+	}
+	if err := m.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+	if c := countMigrations(t, session); c != 4 {
+		t.Fatal("expected 4 migration got", c)
+	}
+	for _, name := range []string{"users/0.cql", "users/1.cql", "billing/0.cql", "billing/1.cql"} {
+		if done := migrationDone(t, session, name); done != 1 {
+			t.Fatalf("migration %s done=%d expected 1", name, done)
+		}
+	}
+}
+
+func TestMigrationObserver(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	var events []string
+	m := migrate.Migrator{
+		Observer: migrate.ObserverFunc(func(ctx context.Context, ev migrate.Event) {
+			events = append(events, fmt.Sprintf("%s %s %d", ev.Type, ev.Migration, ev.Index))
+		}),
+	}
+
+	migrate.Callback = func(ctx context.Context, session gocqlx.Session, ev migrate.CallbackEvent, name string) error {
+		return nil
+	}
+	defer func() {
+		migrate.Callback = nil
+	}()
+
+	f := makeTestFS(t, 1)
+	writeFile(t, f, 1, fmt.Sprintf(insertMigrate, 1)+";\n-- CALL Foo;\n")
+
+	if err := m.FromFS(context.Background(), session, f); err != nil {
+		t.Fatal(err)
+	}
+
+	golden := []string{
+		"migration started 0.cql 0",
+		"statement started 0.cql 1",
+		"statement finished 0.cql 1",
+		"migration finished 0.cql 0",
+		"migration started 1.cql 0",
+		"statement started 1.cql 1",
+		"statement finished 1.cql 1",
+		"callback started 1.cql 2",
+		"callback finished 1.cql 2",
+		"migration finished 1.cql 0",
+		"schema agreement started  0",
+		"schema agreement finished  0",
+	}
+	if diff := cmp.Diff(golden, events); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestMigrationTemplate(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	ctx := context.Background()
+
+	f := memfs.New()
+	writeFile(t, f, 0, "INSERT INTO {{.Keyspace}}.migrate_table (testint, testuuid) VALUES ({{.Value}}, now());")
+
+	m := migrate.Migrator{
+		TemplateData: map[string]interface{}{"Keyspace": "gocqlx_test", "Value": 1},
+	}
+	if err := m.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+	if c := countMigrations(t, session); c != 1 {
+		t.Fatal("expected 1 migration got", c)
+	}
+
+	m.TemplateData = map[string]interface{}{"Keyspace": "gocqlx_test", "Value": 2}
+	if err := m.FromFS(ctx, session, f); err == nil || !strings.Contains(err.Error(), "tampered") {
+		t.Fatal("expected error")
+	}
+}
+
+func TestMigrationBaseline(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	ctx := context.Background()
+
+	f := makeTestFS(t, 4)
+	if err := migrate.Baseline(ctx, session, f, "2.cql"); err != nil {
+		t.Fatal(err)
+	}
+	if c := countMigrations(t, session); c != 0 {
+		t.Fatal("expected 0 migration got", c)
+	}
+	if err := migrate.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+	if c := countMigrations(t, session); c != 1 {
+		t.Fatal("expected 1 migration got", c)
+	}
+
+	if err := migrate.Baseline(ctx, session, f, "9.cql"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatal("expected error")
+	}
+}
+
+func TestMigrationSquash(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	ctx := context.Background()
+
+	// existing database has the history
+	f := makeTestFS(t, 3)
+	if err := migrate.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := "-- +squash 2.cql\n" + fmt.Sprintf(insertMigrate, 100) + ";"
+	squashed := memfs.New()
+	if err := squashed.WriteFile("2_snapshot.cql", []byte(snapshot), fs.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, squashed, 3, fmt.Sprintf(insertMigrate, 3)+";")
+
+	if err := migrate.FromFS(ctx, session, squashed); err != nil {
+		t.Fatal(err)
+	}
+	if c := countMigrations(t, session); c != 4 {
+		t.Fatal("expected 4 migration got", c)
+	}
+
+	status, err := migrate.Status(ctx, session, squashed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range status {
+		if s.Squashed {
+			names = append(names, s.Name)
+		}
+	}
+	if diff := cmp.Diff([]string{"0.cql", "1.cql", "2.cql", "2_snapshot.cql"}, names); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// new database applies the snapshot
+	recreateTables(t, session)
+	if err := migrate.FromFS(ctx, session, squashed); err != nil {
+		t.Fatal(err)
+	}
+	if c := countMigrations(t, session); c != 2 {
+		t.Fatal("expected 2 migration got", c)
+	}
+	if done := migrationDone(t, session, "2_snapshot.cql"); done != 1 {
+		t.Fatalf("migration 2_snapshot.cql done=%d expected 1", done)
+	}
+}
+
+func TestMigrationInfoTable(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	if err := session.ExecStmt("DROP TABLE IF EXISTS gocqlx_test.migrate_info"); err != nil {
+		t.Fatal(err)
+	}
+	// table created by an older version
+	const oldSchema = `CREATE TABLE gocqlx_test.migrate_info (
+	name text,
+	checksum text,
+	done int,
+	start_time timestamp,
+	end_time timestamp,
+	PRIMARY KEY(name)
+)`
+	if err := session.ExecStmt(oldSchema); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	m := migrate.Migrator{Keyspace: "gocqlx_test", Table: "migrate_info"}
+
+	f := makeTestFS(t, 2)
+	writeFile(t, f, 2, fmt.Sprintf(insertMigrate, 2)+";\nSELECT * FROM gocqlx_test.no_such_table;")
+	if err := m.FromFS(ctx, session, f); err == nil {
+		t.Fatal("expected error")
+	}
+
+	v, err := m.List(ctx, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v) != 3 {
+		t.Fatalf("expected 3 migrations got %d", len(v))
+	}
+	for _, info := range v {
+		if info.AppliedBy == "" {
+			t.Fatalf("migration %s missing applied by", info.Name)
+		}
+	}
+	if v[2].Done != 1 || v[2].Error == "" {
+		t.Fatalf("migration %s done=%d error=%q expected failed statement 2", v[2].Name, v[2].Done, v[2].Error)
+	}
+
+	if l, err := migrate.List(ctx, session); err != nil {
+		t.Fatal(err)
+	} else if len(l) != 0 {
+		t.Fatalf("expected no migrations in the default table got %d", len(l))
+	}
+}
+
+func TestMigrationInfoTableOnce(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	if err := session.ExecStmt("DROP TABLE IF EXISTS gocqlx_test.migrate_once"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	m := migrate.Migrator{Keyspace: "gocqlx_test", Table: "migrate_once"}
+
+	// List does not create the table
+	if v, err := m.List(ctx, session); err != nil {
+		t.Fatal(err)
+	} else if len(v) != 0 {
+		t.Fatalf("expected no migrations got %d", len(v))
+	}
+	if err := session.ExecStmt("SELECT * FROM gocqlx_test.migrate_once"); err == nil {
+		t.Fatal("expected table not to exist")
+	}
+
+	f := makeTestFS(t, 1)
+	if err := m.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+
+	// table is set up once per Migrator
+	if err := session.ExecStmt("DROP TABLE gocqlx_test.migrate_once"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.FromFS(ctx, session, f); err == nil {
+		t.Fatal("expected error")
+	}
+	m2 := migrate.Migrator{Keyspace: "gocqlx_test", Table: "migrate_once"}
+	if err := m2.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrationTimeout(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	ctx := context.Background()
+
+	f := memfs.New()
+	writeFile(t, f, 0, "-- +timeout 1ns\n"+fmt.Sprintf(insertMigrate, 0)+";")
+	err := migrate.FromFS(ctx, session, f)
+	if err == nil || !strings.Contains(err.Error(), "statement 1 at line 2: timed out after 1ns") {
+		t.Fatal("expected timeout error, got", err)
+	}
+
+	m := migrate.Migrator{StatementTimeout: time.Minute}
+	writeFile(t, f, 0, "-- +retries 2\n"+fmt.Sprintf(insertMigrate, 0)+";")
+	if err := m.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+	if c := countMigrations(t, session); c != 1 {
+		t.Fatal("expected 1 migration got", c)
+	}
+}
+
 func TestMigrationNoSemicolon(t *testing.T) {
 	session := gocqlxtest.CreateSession(t)
 	defer session.Close()
@@ -244,57 +654,78 @@ func TestIsCallback(t *testing.T) {
 	}
 }
 
-func TestIsComment(t *testing.T) {
-	table := []struct {
-		Name      string
-		Stmt      string
-		IsComment bool
-	}{
-		{
-			Name:      "CQL statement",
-			Stmt:      "SELECT * from X;",
-			IsComment: false,
-		},
-		{
-			Name:      "Regular comment",
-			Stmt:      "-- This is a comment",
-			IsComment: true,
-		},
-		{
-			Name:      "Comment with additional text",
-			Stmt:      "-- ttl 1 hour",
-			IsComment: true,
-		},
-		{
-			Name:      "Callback command (not a regular comment)",
-			Stmt:      "-- CALL Foo;",
-			IsComment: false,
-		},
-		{
-			Name:      "Callback with spaces (not a regular comment)",
-			Stmt:      "--   CALL Bar;",
-			IsComment: false,
-		},
-		{
-			Name:      "Empty statement",
-			Stmt:      "",
-			IsComment: false,
-		},
-		{
-			Name:      "Whitespace only",
-			Stmt:      "   ",
-			IsComment: false,
-		},
+func TestMigrationResumeCommentNumbering(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	f := memfs.New()
+	// statements are numbered as by the older splitter, comment only chunks count
+	content := "-- first;\n" + fmt.Sprintf(insertMigrate, 0) + ";\n" + fmt.Sprintf(insertMigrate, 1) + ";\n-- end\n"
+	if err := f.WriteFile("0.cql", []byte(content), fs.ModePerm); err != nil {
+		t.Fatal(err)
 	}
 
-	for i := range table {
-		test := table[i]
-		t.Run(test.Name, func(t *testing.T) {
-			result := migrate.IsComment(test.Stmt)
-			if result != test.IsComment {
-				t.Errorf("IsComment(%q) = %v, expected %v", test.Stmt, result, test.IsComment)
-			}
-		})
+	ctx := context.Background()
+	if err := migrate.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+	if done := migrationDone(t, session, "0.cql"); done != 4 {
+		t.Fatalf("migration done=%d expected 4", done)
+	}
+
+	// simulate failure of the last insert
+	if err := session.ExecStmt("TRUNCATE gocqlx_test.migrate_table"); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Query("UPDATE gocqlx_test.gocqlx_migrate SET done = 2 WHERE name = ?", nil).Bind("0.cql").Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrate.FromFS(ctx, session, f); err != nil {
+		t.Fatalf("resume migration: %v", err)
+	}
+	if done := migrationDone(t, session, "0.cql"); done != 4 {
+		t.Fatalf("resumed migration done=%d expected 4", done)
+	}
+	if count := countMigrations(t, session); count != 1 {
+		t.Fatalf("migration statements executed after resume=%d expected 1", count)
+	}
+}
+
+func TestMigrationResumeEditedComment(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	defer func() {
+		migrate.DefaultChecksumAlgorithm = migrate.ChecksumSHA256
+	}()
+	migrate.DefaultChecksumAlgorithm = migrate.ChecksumSHA256Normalized
+
+	f := memfs.New()
+	content := "-- first;\n" + fmt.Sprintf(insertMigrate, 0) + ";\n" + fmt.Sprintf(insertMigrate, 1) + ";\n"
+	if err := f.WriteFile("0.cql", []byte(content), fs.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := migrate.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate failure of the last insert
+	if err := session.Query("UPDATE gocqlx_test.gocqlx_migrate SET done = 2 WHERE name = ?", nil).Bind("0.cql").Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	// removing the semicolon from the comment shifts statement numbering
+	content = "-- first\n" + fmt.Sprintf(insertMigrate, 0) + ";\n" + fmt.Sprintf(insertMigrate, 1) + ";\n"
+	if err := f.WriteFile("0.cql", []byte(content), fs.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrate.FromFS(ctx, session, f); err == nil || !strings.Contains(err.Error(), "tampered") {
+		t.Fatal("expected error, got", err)
 	}
 }
 
@@ -383,6 +814,104 @@ func TestMigrationCallback(t *testing.T) {
 	})
 }
 
+func TestMigrationFunc(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	calls := 0
+	fail := true
+	migrate.Register("1_backfill", func(ctx context.Context, session gocqlx.Session) error {
+		calls++
+		if fail {
+			return errors.New("backfill failed")
+		}
+		return session.ExecStmt(fmt.Sprintf(insertMigrate, 100))
+	})
+	defer migrate.Unregister("1_backfill")
+
+	f := makeTestFS(t, 3)
+	ctx := context.Background()
+
+	err := migrate.FromFS(ctx, session, f)
+	if err == nil || !strings.Contains(err.Error(), `apply migration "1_backfill": backfill failed`) {
+		t.Fatalf("FromFS() error=%v expected backfill failure", err)
+	}
+	if c := countMigrations(t, session); c != 2 {
+		t.Fatal("expected 2 migration got", c)
+	}
+
+	fail = false
+	if err := migrate.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+	if c := countMigrations(t, session); c != 4 {
+		t.Fatal("expected 4 migration got", c)
+	}
+	if done := migrationDone(t, session, "1_backfill"); done != 1 {
+		t.Fatalf("migration done=%d expected 1", done)
+	}
+
+	if err := migrate.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("migration calls=%d expected 2", calls)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	session := gocqlxtest.CreateSession(t)
+	defer session.Close()
+	recreateTables(t, session)
+
+	ctx := context.Background()
+
+	f := makeTestFS(t, 2)
+	if err := migrate.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, f, 2, fmt.Sprintf(insertMigrate, 2)+";\n-- CALL Foo;\n")
+
+	status, err := migrate.Status(ctx, session, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 3 {
+		t.Fatal("expected 3 migrations got", len(status))
+	}
+	for i, s := range status[:2] {
+		if s.Pending() || s.Done() != 1 || s.Steps() != 1 {
+			t.Fatalf("migration %d pending=%v done=%d steps=%d", i, s.Pending(), s.Done(), s.Steps())
+		}
+	}
+	if s := status[2]; !s.Pending() || s.Applied != nil || s.Steps() != 2 || s.Statements[1] != "-- CALL Foo;" {
+		t.Fatalf("unexpected status %+v", s)
+	}
+
+	if err := migrate.MarkApplied(ctx, session, f, "2.cql"); err != nil {
+		t.Fatal(err)
+	}
+	if done := migrationDone(t, session, "2.cql"); done != 2 {
+		t.Fatalf("migration done=%d expected 2", done)
+	}
+	if c := countMigrations(t, session); c != 2 {
+		t.Fatal("expected 2 migration got", c)
+	}
+
+	writeFile(t, f, 1, "SELECT * FROM bla;")
+	if err := migrate.MarkApplied(ctx, session, f, "1.cql"); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrate.FromFS(ctx, session, f); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrate.MarkApplied(ctx, session, f, "3.cql"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
 func TestMigrationRecordsProgressAfterContextCanceled(t *testing.T) {
 	session := gocqlxtest.CreateSession(t)
 	defer session.Close()
@@ -707,6 +1236,16 @@ func migrationDone(tb testing.TB, session gocqlx.Session, name string) int {
 	return v
 }
 
+func migrationChecksum(tb testing.TB, session gocqlx.Session, name string) string {
+	tb.Helper()
+
+	var v string
+	if err := session.Query("SELECT checksum FROM gocqlx_test.gocqlx_migrate WHERE name = ?", nil).Bind(name).Get(&v); err != nil {
+		tb.Fatal(err)
+	}
+	return v
+}
+
 func makeTestFS(tb testing.TB, n int) *memfs.FS {
 	tb.Helper()
 	f := memfs.New()
@@ -0,0 +1,173 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/scylladb/gocqlx/v3"
+)
+
+// EventType specifies type of the migration Event.
+type EventType uint8
+
+// enumeration of EventTypes
+const (
+	MigrationStarted EventType = iota
+	MigrationResumed
+	MigrationFinished
+	StatementStarted
+	StatementFinished
+	CallbackStarted
+	CallbackFinished
+	SchemaAgreementStarted
+	SchemaAgreementFinished
+)
+
+var eventTypeNames = [...]string{
+	MigrationStarted:        "migration started",
+	MigrationResumed:        "migration resumed",
+	MigrationFinished:       "migration finished",
+	StatementStarted:        "statement started",
+	StatementFinished:       "statement finished",
+	CallbackStarted:         "callback started",
+	CallbackFinished:        "callback finished",
+	SchemaAgreementStarted:  "schema agreement started",
+	SchemaAgreementFinished: "schema agreement finished",
+}
+
+func (t EventType) String() string {
+	if int(t) < len(eventTypeNames) {
+		return eventTypeNames[t]
+	}
+	return "unknown"
+}
+
+// Event describes progress of a migration.
+type Event struct {
+	// Err is the error of a finished migration, statement, callback or
+	// schema agreement wait.
+	Err error
+	// Migration is the migration name, it's empty for the schema agreement
+	// wait after all migrations are applied.
+	Migration string
+	// Statement is the CQL statement for statement events and the callback
+	// name for callback events.
+	Statement string
+	// Duration is set for finished events.
+	Duration time.Duration
+	// Index is the statement number in the migration file starting from 1.
+	// For MigrationResumed it's the number of statements already done.
+	Index int
+	// Line is the line number the statement starts at.
+	Line int
+	Type EventType
+}
+
+// Observer receives migration events. It is called synchronously from
+// the migrating goroutine. Events of a migration file are:
+//
+//	MigrationStarted or MigrationResumed
+//	(StatementStarted StatementFinished | CallbackStarted CallbackFinished)...
+//	MigrationFinished
+//
+// SchemaAgreementStarted and SchemaAgreementFinished surround every schema
+// agreement wait.
+type Observer interface {
+	ObserveMigration(ctx context.Context, ev Event)
+}
+
+// ObserverFunc is an adapter to allow the use of ordinary functions as Observer.
+type ObserverFunc func(ctx context.Context, ev Event)
+
+// ObserveMigration calls f(ctx, ev).
+func (f ObserverFunc) ObserveMigration(ctx context.Context, ev Event) {
+	f(ctx, ev)
+}
+
+// SlogObserver returns an Observer that logs events to l.
+// Started events are logged at debug level, finished events at info level
+// or error level if they failed.
+func SlogObserver(l *slog.Logger) Observer {
+	return ObserverFunc(func(ctx context.Context, ev Event) {
+		level := slog.LevelDebug
+		switch ev.Type {
+		case MigrationResumed, MigrationFinished, StatementFinished, CallbackFinished, SchemaAgreementFinished:
+			level = slog.LevelInfo
+		}
+		if ev.Err != nil {
+			level = slog.LevelError
+		}
+		if !l.Enabled(ctx, level) {
+			return
+		}
+
+		attrs := make([]slog.Attr, 0, 6)
+		if ev.Migration != "" {
+			attrs = append(attrs, slog.String("migration", ev.Migration))
+		}
+		if ev.Index != 0 {
+			attrs = append(attrs, slog.Int("index", ev.Index))
+		}
+		if ev.Line != 0 {
+			attrs = append(attrs, slog.Int("line", ev.Line))
+		}
+		switch ev.Type {
+		case StatementStarted, StatementFinished:
+			attrs = append(attrs, slog.String("statement", ev.Statement))
+		case CallbackStarted, CallbackFinished:
+			attrs = append(attrs, slog.String("callback", ev.Statement))
+		}
+		if ev.Duration != 0 {
+			attrs = append(attrs, slog.Duration("duration", ev.Duration))
+		}
+		if ev.Err != nil {
+			attrs = append(attrs, slog.Any("error", ev.Err))
+		}
+		l.LogAttrs(ctx, level, ev.Type.String(), attrs...)
+	})
+}
+
+func (mg *Migrator) observe(ctx context.Context, ev Event) {
+	if mg.Observer != nil {
+		mg.Observer.ObserveMigration(ctx, ev)
+	}
+}
+
+// observeStep runs f surrounded by started and finished events.
+func (mg *Migrator) observeStep(ctx context.Context, ev Event, started, finished EventType, f func() error) error {
+	ev.Type = started
+	mg.observe(ctx, ev)
+
+	start := time.Now()
+	err := f()
+
+	ev.Type = finished
+	ev.Duration = time.Since(start)
+	ev.Err = err
+	mg.observe(ctx, ev)
+
+	return err
+}
+
+func (mg *Migrator) awaitSchemaAgreement(ctx context.Context, session gocqlx.Session, name string, timeout time.Duration) error {
+	ev := Event{Migration: name}
+	return mg.observeStep(ctx, ev, SchemaAgreementStarted, SchemaAgreementFinished, func() error {
+		if timeout <= 0 {
+			return session.AwaitSchemaAgreement(ctx)
+		}
+		tctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		err := session.AwaitSchemaAgreement(tctx)
+		if err != nil && ctx.Err() == nil && errors.Is(tctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s: %w", timeout, err)
+		}
+		return err
+	})
+}
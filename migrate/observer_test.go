@@ -0,0 +1,79 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSlogObserver(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	o := SlogObserver(l)
+
+	ctx := context.Background()
+	events := []Event{
+		{Type: MigrationStarted, Migration: "0.cql"},
+		{Type: StatementStarted, Migration: "0.cql", Index: 1, Line: 2, Statement: "SELECT * FROM foo"},
+		{Type: StatementFinished, Migration: "0.cql", Index: 1, Line: 2, Statement: "SELECT * FROM foo", Duration: time.Second},
+		{Type: CallbackFinished, Migration: "0.cql", Index: 2, Line: 3, Statement: "Foo", Duration: time.Millisecond, Err: errors.New("boom")},
+		{Type: MigrationResumed, Migration: "1.cql", Index: 2},
+		{Type: SchemaAgreementFinished, Duration: time.Minute},
+	}
+	for _, ev := range events {
+		o.ObserveMigration(ctx, ev)
+	}
+
+	golden := `level=INFO msg="statement finished" migration=0.cql index=1 line=2 statement="SELECT * FROM foo" duration=1s
+level=ERROR msg="callback finished" migration=0.cql index=2 line=3 callback=Foo duration=1ms error=boom
+level=INFO msg="migration resumed" migration=1.cql index=2
+level=INFO msg="schema agreement finished" duration=1m0s
+`
+	if diff := cmp.Diff(golden, buf.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestObserveStep(t *testing.T) {
+	var got []Event
+	mg := &Migrator{
+		Observer: ObserverFunc(func(ctx context.Context, ev Event) {
+			ev.Duration = 0
+			got = append(got, ev)
+		}),
+	}
+
+	errBoom := errors.New("boom")
+	ev := Event{Migration: "0.cql", Index: 1, Statement: "SELECT * FROM foo"}
+	err := mg.observeStep(context.Background(), ev, StatementStarted, StatementFinished, func() error {
+		return errBoom
+	})
+	if err != errBoom {
+		t.Fatal(err)
+	}
+
+	golden := []Event{
+		{Type: StatementStarted, Migration: "0.cql", Index: 1, Statement: "SELECT * FROM foo"},
+		{Type: StatementFinished, Migration: "0.cql", Index: 1, Statement: "SELECT * FROM foo", Err: errBoom},
+	}
+	if diff := cmp.Diff(golden, got, cmp.Comparer(func(a, b error) bool { return a == b })); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/scylladb/gocqlx/v3"
+)
+
+// MigrationFunc is a migration implemented in Go, see Register.
+type MigrationFunc func(ctx context.Context, session gocqlx.Session) error
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]MigrationFunc)
+)
+
+// Register adds a Go code migration. The name takes part in lexicographical
+// ordering together with names of CQL files, i.e. migration "005_backfill"
+// runs after "004_schema.cql" and before "006_schema.cql".
+//
+// Go code migration is recorded as done only after it returns without error.
+// If it fails it is run again by the next migration, so it should be safe
+// to rerun. It receives the caller's context.
+//
+// With Migrator.Namespaced a "<dir>/" name prefix puts the migration into
+// the subdirectory, i.e. "users/005_backfill".
+//
+// Register panics if name is empty, has the .cql extension or is registered twice.
+func Register(name string, f MigrationFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" || strings.HasSuffix(name, ".cql") {
+		panic(fmt.Sprintf("migrate: invalid migration name %q", name))
+	}
+	if f == nil {
+		panic("migrate: Register migration is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic(fmt.Sprintf("migrate: Register called twice for migration %q", name))
+	}
+	registry[name] = f
+}
+
+// funcChecksum is recorded as checksum of Go code migrations.
+const funcChecksum = "go"
+
+// migration is either a CQL file or a registered Go code migration.
+type migration struct {
+	fn   MigrationFunc
+	name string
+}
+
+func (m migration) checksum(f fs.FS, alg ChecksumAlgorithm) (string, error) {
+	if m.fn != nil {
+		return funcChecksum, nil
+	}
+	return fileChecksum(f, m.name, alg)
+}
+
+// statements returns statements of a CQL file, it returns nil for Go code
+// migrations.
+func (m migration) statements(f fs.FS) ([]statement, error) {
+	if m.fn != nil {
+		return nil, nil
+	}
+	b, err := fs.ReadFile(f, m.name)
+	if err != nil {
+		return nil, err
+	}
+	return splitStatements(b)
+}
+
+// steps returns number of steps recorded as Done when the migration is complete.
+func (m migration) steps(f fs.FS) (int, error) {
+	if m.fn != nil {
+		return 1, nil
+	}
+	stmts, err := m.statements(f)
+	return len(stmts), err
+}
+
+// listMigrations returns CQL files from f and registered Go code migrations
+// ordered by namespace and name. If namespaced is set, files in subdirectories
+// and Go code migrations with "<dir>/" name prefix are included.
+func listMigrations(f fs.FS, namespaced bool) ([]migration, error) {
+	fm, err := fs.Glob(f, "*.cql")
+	if err != nil {
+		return nil, err
+	}
+	if namespaced {
+		sub, err := fs.Glob(f, "*/*.cql")
+		if err != nil {
+			return nil, err
+		}
+		fm = append(fm, sub...)
+	}
+
+	v := make([]migration, 0, len(fm))
+	for _, name := range fm {
+		v = append(v, migration{name: name})
+	}
+
+	registryMu.Lock()
+	for name, fn := range registry {
+		if n := strings.Count(name, "/"); n > 1 || (n == 1 && !namespaced) {
+			continue
+		}
+		v = append(v, migration{name: name, fn: fn})
+	}
+	registryMu.Unlock()
+
+	sort.Slice(v, func(i, j int) bool {
+		ni, nj := namespace(v[i].name), namespace(v[j].name)
+		if ni != nj {
+			return ni < nj
+		}
+		return v[i].name < v[j].name
+	})
+
+	return v, nil
+}
+
+// namespace returns directory part of a migration name.
+func namespace(name string) string {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
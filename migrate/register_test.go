@@ -0,0 +1,134 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/psanford/memfs"
+
+	"github.com/scylladb/gocqlx/v3"
+)
+
+func TestListMigrations(t *testing.T) {
+	noop := func(context.Context, gocqlx.Session) error { return nil }
+	Register("1_backfill", noop)
+	Register("3_backfill", noop)
+	defer Unregister("1_backfill")
+	defer Unregister("3_backfill")
+
+	f := memfs.New()
+	for _, name := range []string{"0.cql", "2.cql", "4.cql", "notes.txt"} {
+		if err := f.WriteFile(name, []byte("SELECT * FROM foo;"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v, err := listMigrations(f, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		names []string
+		funcs []bool
+	)
+	for _, m := range v {
+		names = append(names, m.name)
+		funcs = append(funcs, m.fn != nil)
+	}
+	if diff := cmp.Diff([]string{"0.cql", "1_backfill", "2.cql", "3_backfill", "4.cql"}, names); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff([]bool{false, true, false, true, false}, funcs); diff != "" {
+		t.Fatal(diff)
+	}
+
+	c, err := v[1].checksum(f, ChecksumSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != funcChecksum {
+		t.Fatalf("checksum=%s expected %s", c, funcChecksum)
+	}
+}
+
+func TestListMigrationsNamespaced(t *testing.T) {
+	noop := func(context.Context, gocqlx.Session) error { return nil }
+	Register("b/1_backfill", noop)
+	defer Unregister("b/1_backfill")
+
+	a := memfs.New()
+	b := memfs.New()
+	for _, f := range []*memfs.FS{a, b} {
+		for _, name := range []string{"0.cql", "2.cql"} {
+			if err := f.WriteFile(name, []byte("SELECT * FROM foo;"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	f := MergeFS(map[string]fs.FS{"a": a, "b": b})
+
+	v, err := listMigrations(f, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range v {
+		names = append(names, m.name)
+	}
+	golden := []string{"a/0.cql", "a/2.cql", "b/0.cql", "b/1_backfill", "b/2.cql"}
+	if diff := cmp.Diff(golden, names); diff != "" {
+		t.Fatal(diff)
+	}
+
+	v, err = listMigrations(f, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v) != 0 {
+		t.Fatalf("expected no migrations got %d", len(v))
+	}
+
+	if _, err := fs.ReadFile(f, "a/2.cql"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.ReadFile(f, "c/2.cql"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("ReadFile() error=%v expected not exist", err)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	noop := func(context.Context, gocqlx.Session) error { return nil }
+	Register("dup", noop)
+	defer Unregister("dup")
+
+	table := []struct {
+		Name string
+		Mig  string
+		Fn   MigrationFunc
+	}{
+		{Name: "empty name", Mig: "", Fn: noop},
+		{Name: "cql extension", Mig: "1.cql", Fn: noop},
+		{Name: "nil func", Mig: "nil", Fn: nil},
+		{Name: "duplicate", Mig: "dup", Fn: noop},
+	}
+
+	for i := range table {
+		test := table[i]
+		t.Run(test.Name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			Register(test.Mig, test.Fn)
+		})
+	}
+}
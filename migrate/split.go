@@ -0,0 +1,316 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind uint8
+
+const (
+	tokenWord      tokenKind = iota // keywords, identifiers and numbers
+	tokenString                     // 'single quoted' string literal
+	tokenDollar                     // $$dollar quoted$$ string literal
+	tokenQuoted                     // "double quoted" identifier
+	tokenSymbol                     // any other single character
+	tokenSemicolon                  // ;
+	tokenComment                    // -- line, // line or /* block */ comment
+	tokenSpace                      // run of whitespace
+)
+
+// token is a lexical element of a migration file.
+type token struct {
+	text  string
+	start int
+	end   int
+	line  int
+	kind  tokenKind
+}
+
+// significant reports whether token carries meaning for the statement,
+// comments and whitespace do not.
+func (t token) significant() bool {
+	return t.kind != tokenComment && t.kind != tokenSpace
+}
+
+// isWord reports whether token is a word equal to w, ignoring case.
+func (t token) isWord(w string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, w)
+}
+
+// lex splits src into tokens. Every byte of src belongs to exactly one token.
+func lex(src string) ([]token, error) {
+	var (
+		tokens []token
+		line   = 1
+	)
+
+	for pos := 0; pos < len(src); {
+		t := token{start: pos, line: line}
+
+		c := src[pos]
+		switch {
+		case isSpace(c):
+			t.kind = tokenSpace
+			for pos < len(src) && isSpace(src[pos]) {
+				pos++
+			}
+		case c == '-' && strings.HasPrefix(src[pos:], "--"), c == '/' && strings.HasPrefix(src[pos:], "//"):
+			t.kind = tokenComment
+			if i := strings.IndexByte(src[pos:], '\n'); i >= 0 {
+				pos += i
+			} else {
+				pos = len(src)
+			}
+		case c == '/' && strings.HasPrefix(src[pos:], "/*"):
+			t.kind = tokenComment
+			i := strings.Index(src[pos+2:], "*/")
+			if i < 0 {
+				return nil, fmt.Errorf("line %d: unterminated block comment", line)
+			}
+			pos += i + 4
+		case c == '$' && strings.HasPrefix(src[pos:], "$$"):
+			t.kind = tokenDollar
+			i := strings.Index(src[pos+2:], "$$")
+			if i < 0 {
+				return nil, fmt.Errorf("line %d: unterminated $$ string", line)
+			}
+			pos += i + 4
+		case c == '\'' || c == '"':
+			if c == '\'' {
+				t.kind = tokenString
+			} else {
+				t.kind = tokenQuoted
+			}
+			end, ok := quoteEnd(src, pos, c)
+			if !ok {
+				if c == '\'' {
+					return nil, fmt.Errorf("line %d: unterminated string literal", line)
+				}
+				return nil, fmt.Errorf("line %d: unterminated quoted identifier", line)
+			}
+			pos = end
+		case c == ';':
+			t.kind = tokenSemicolon
+			pos++
+		case isWordByte(c):
+			t.kind = tokenWord
+			for pos < len(src) && isWordByte(src[pos]) {
+				pos++
+			}
+		default:
+			t.kind = tokenSymbol
+			pos++
+		}
+
+		t.end = pos
+		t.text = src[t.start:t.end]
+		line += strings.Count(t.text, "\n")
+		tokens = append(tokens, t)
+	}
+
+	return tokens, nil
+}
+
+// quoteEnd returns position after the closing quote of a literal starting at
+// pos. A doubled quote character is an escaped quote.
+func quoteEnd(src string, pos int, quote byte) (int, bool) {
+	for i := pos + 1; i < len(src); i++ {
+		if src[i] != quote {
+			continue
+		}
+		if i+1 < len(src) && src[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1, true
+	}
+	return 0, false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// statement is a single unit of work read from a migration file, either
+// a CQL statement, a callback comment or a no-op.
+type statement struct {
+	// text is the CQL statement without the terminating semicolon and
+	// surrounding comments, for a no-op it's the source text.
+	text string
+	// callback is the name from a `-- CALL <name>;` comment, if set text is empty.
+	callback string
+	// noop is set for comment only or empty statements, they are not
+	// executed but count towards Info.Done.
+	noop bool
+	// tokens are the significant tokens of the statement.
+	tokens []token
+	// line is the line number the statement starts at.
+	line int
+}
+
+// splitStatements splits migration file contents into statements.
+// Semicolons terminate statements unless they are inside string literals,
+// $$ strings, quoted identifiers, comments or a BEGIN BATCH ... APPLY BATCH
+// block. Comments in form `-- CALL <name>;` between statements are returned
+// as callback statements.
+//
+// Info.Done of migrations applied by older versions counts every semicolon
+// terminated chunk and a non-blank tail of the file. To resume them
+// correctly, empty statements, semicolons in comments between statements
+// and comments after the last statement yield no-op statements.
+func splitStatements(b []byte) ([]statement, error) {
+	src := string(b)
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		stmts []statement
+		cur   []token
+		// start is the offset of text following the last statement.
+		start int
+	)
+
+	noop := func(end int) {
+		text := strings.TrimSpace(src[start:end])
+		offset := start + strings.Index(src[start:end], text)
+		stmts = append(stmts, statement{
+			text: text,
+			noop: true,
+			line: 1 + strings.Count(src[:offset], "\n"),
+		})
+		start = end
+	}
+
+	flush := func() {
+		if len(cur) == 0 {
+			return
+		}
+		first, last := cur[0], cur[len(cur)-1]
+		stmts = append(stmts, statement{
+			text:   src[first.start:last.end],
+			tokens: cur,
+			line:   first.line,
+		})
+		cur = nil
+	}
+
+	for _, t := range tokens {
+		switch {
+		case t.kind == tokenComment:
+			name := isCallback(strings.TrimSpace(t.text))
+			if name == "" {
+				if len(cur) == 0 {
+					for i, c := range []byte(t.text) {
+						if c == ';' {
+							noop(t.start + i + 1)
+						}
+					}
+				}
+				continue
+			}
+			if len(cur) > 0 {
+				return nil, fmt.Errorf("line %d: callback %q inside statement starting at line %d", t.line, name, cur[0].line)
+			}
+			stmts = append(stmts, statement{
+				callback: name,
+				line:     t.line,
+			})
+			start = t.end
+		case t.kind == tokenSpace:
+			continue
+		case t.kind == tokenSemicolon:
+			if isBatch(cur) && !endsBatch(cur) {
+				cur = append(cur, t)
+				continue
+			}
+			if len(cur) == 0 {
+				noop(t.end)
+				continue
+			}
+			flush()
+			start = t.end
+		default:
+			cur = append(cur, t)
+		}
+	}
+
+	if isBatch(cur) && !endsBatch(cur) {
+		return nil, fmt.Errorf("line %d: missing APPLY BATCH", cur[0].line)
+	}
+	// handle missing semicolon after last statement
+	if len(cur) > 0 {
+		flush()
+	} else if strings.TrimSpace(src[start:]) != "" {
+		noop(len(src))
+	}
+
+	return stmts, nil
+}
+
+func isBatch(tokens []token) bool {
+	return len(tokens) > 0 && tokens[0].isWord("BEGIN")
+}
+
+func endsBatch(tokens []token) bool {
+	n := len(tokens)
+	return n >= 2 && tokens[n-2].isWord("APPLY") && tokens[n-1].isWord("BATCH")
+}
+
+// directives returns `-- +<name> <value>` comments placed before the first
+// statement of a migration file, i.e. `-- +squash 100_users.cql`.
+func directives(b []byte) (map[string]string, error) {
+	tokens, err := lex(string(b))
+	if err != nil {
+		return nil, err
+	}
+
+	var v map[string]string
+	for _, t := range tokens {
+		if t.significant() {
+			break
+		}
+		if t.kind != tokenComment || !strings.HasPrefix(t.text, "--") {
+			continue
+		}
+		d := strings.TrimSpace(strings.TrimPrefix(t.text, "--"))
+		if !strings.HasPrefix(d, "+") {
+			continue
+		}
+		name, value, _ := strings.Cut(d[1:], " ")
+		if v == nil {
+			v = make(map[string]string)
+		}
+		v[name] = strings.TrimSpace(value)
+	}
+	return v, nil
+}
+
+// containsWords reports whether tokens contain the words in sequence,
+// ignoring case.
+func containsWords(tokens []token, words ...string) bool {
+	for i := 0; i+len(words) <= len(tokens); i++ {
+		ok := true
+		for j, w := range words {
+			if !tokens[i+j].isWord(w) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
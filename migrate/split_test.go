@@ -0,0 +1,203 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitStatements(t *testing.T) {
+	type stmt struct {
+		Text     string
+		Callback string
+		Noop     bool
+		Line     int
+	}
+
+	table := []struct {
+		Name   string
+		Input  string
+		Output []stmt
+	}{
+		{
+			Name:  "simple",
+			Input: "CREATE TABLE foo (id int PRIMARY KEY);\nINSERT INTO foo (id) VALUES (1);\n",
+			Output: []stmt{
+				{Text: "CREATE TABLE foo (id int PRIMARY KEY)", Line: 1},
+				{Text: "INSERT INTO foo (id) VALUES (1)", Line: 2},
+			},
+		},
+		{
+			Name:  "missing semicolon",
+			Input: "INSERT INTO foo (id) VALUES (1);INSERT INTO foo (id) VALUES (2)",
+			Output: []stmt{
+				{Text: "INSERT INTO foo (id) VALUES (1)", Line: 1},
+				{Text: "INSERT INTO foo (id) VALUES (2)", Line: 1},
+			},
+		},
+		{
+			Name:  "string literal",
+			Input: "INSERT INTO foo (id, t) VALUES (1, 'a;b''c;');",
+			Output: []stmt{
+				{Text: "INSERT INTO foo (id, t) VALUES (1, 'a;b''c;')", Line: 1},
+			},
+		},
+		{
+			Name:  "dollar string",
+			Input: "CREATE FUNCTION f(x int) RETURNS NULL ON NULL INPUT RETURNS int LANGUAGE lua AS $$\nreturn x;\n$$;\nSELECT * FROM foo;",
+			Output: []stmt{
+				{Text: "CREATE FUNCTION f(x int) RETURNS NULL ON NULL INPUT RETURNS int LANGUAGE lua AS $$\nreturn x;\n$$", Line: 1},
+				{Text: "SELECT * FROM foo", Line: 4},
+			},
+		},
+		{
+			Name:  "quoted identifier",
+			Input: `SELECT "a;""b" FROM foo;`,
+			Output: []stmt{
+				{Text: `SELECT "a;""b" FROM foo`, Line: 1},
+			},
+		},
+		{
+			Name:  "comments",
+			Input: "-- comment;\n// comment;\n/* multi\nline; comment */\nSELECT * FROM foo; -- ttl 1 hour\n",
+			Output: []stmt{
+				{Text: "-- comment;", Noop: true, Line: 1},
+				{Text: "// comment;", Noop: true, Line: 2},
+				{Text: "/* multi\nline;", Noop: true, Line: 3},
+				{Text: "SELECT * FROM foo", Line: 5},
+				{Text: "-- ttl 1 hour", Noop: true, Line: 5},
+			},
+		},
+		{
+			Name:  "comment before statement",
+			Input: "-- users\nCREATE TABLE users (id int PRIMARY KEY);\n",
+			Output: []stmt{
+				{Text: "CREATE TABLE users (id int PRIMARY KEY)", Line: 2},
+			},
+		},
+		{
+			Name:  "comment inside statement",
+			Input: "SELECT *\n/* ; */ FROM foo -- ;\n;",
+			Output: []stmt{
+				{Text: "SELECT *\n/* ; */ FROM foo", Line: 1},
+			},
+		},
+		{
+			Name:  "callbacks",
+			Input: "\n-- CALL Foo;\nSELECT * FROM foo;\n--CALL Bar;\n",
+			Output: []stmt{
+				{Callback: "Foo", Line: 2},
+				{Text: "SELECT * FROM foo", Line: 3},
+				{Callback: "Bar", Line: 4},
+			},
+		},
+		{
+			Name:  "batch",
+			Input: "BEGIN UNLOGGED BATCH\nINSERT INTO foo (id) VALUES (1);\nINSERT INTO foo (id) VALUES (2);\napply batch;\nSELECT * FROM foo;",
+			Output: []stmt{
+				{Text: "BEGIN UNLOGGED BATCH\nINSERT INTO foo (id) VALUES (1);\nINSERT INTO foo (id) VALUES (2);\napply batch", Line: 1},
+				{Text: "SELECT * FROM foo", Line: 5},
+			},
+		},
+		{
+			Name:  "empty",
+			Input: "\n-- nothing here\n;;\n",
+			Output: []stmt{
+				{Text: "-- nothing here\n;", Noop: true, Line: 2},
+				{Text: ";", Noop: true, Line: 3},
+			},
+		},
+		{
+			Name:   "blank",
+			Input:  "\n \n",
+			Output: nil,
+		},
+	}
+
+	for i := range table {
+		test := table[i]
+		t.Run(test.Name, func(t *testing.T) {
+			stmts, err := splitStatements([]byte(test.Input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []stmt
+			for _, s := range stmts {
+				got = append(got, stmt{Text: s.text, Callback: s.callback, Noop: s.noop, Line: s.line})
+			}
+			if diff := cmp.Diff(test.Output, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestSplitStatementsError(t *testing.T) {
+	table := []struct {
+		Name  string
+		Input string
+		Err   string
+	}{
+		{
+			Name:  "unterminated string",
+			Input: "SELECT * FROM foo;\nINSERT INTO foo (t) VALUES ('a);",
+			Err:   "line 2: unterminated string literal",
+		},
+		{
+			Name:  "unterminated quoted identifier",
+			Input: `SELECT "a FROM foo;`,
+			Err:   "line 1: unterminated quoted identifier",
+		},
+		{
+			Name:  "unterminated dollar string",
+			Input: "\nCREATE FUNCTION f() AS $$ return 1;",
+			Err:   "line 2: unterminated $$ string",
+		},
+		{
+			Name:  "unterminated block comment",
+			Input: "/* comment",
+			Err:   "line 1: unterminated block comment",
+		},
+		{
+			Name:  "unterminated batch",
+			Input: "BEGIN BATCH\nINSERT INTO foo (id) VALUES (1);",
+			Err:   "line 1: missing APPLY BATCH",
+		},
+		{
+			Name:  "callback inside statement",
+			Input: "SELECT *\n-- CALL Foo;\nFROM foo;",
+			Err:   `line 2: callback "Foo" inside statement starting at line 1`,
+		},
+	}
+
+	for i := range table {
+		test := table[i]
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := splitStatements([]byte(test.Input))
+			if err == nil || !strings.Contains(err.Error(), test.Err) {
+				t.Fatalf("splitStatements() error=%v expected %q", err, test.Err)
+			}
+		})
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	input := "-- Comment\n-- +squash 100_users.cql\n--+timeout   10m \n// +ignored\n-- +flag\nSELECT * FROM foo;\n-- +after statement\n"
+	v, err := directives([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := map[string]string{
+		"squash":  "100_users.cql",
+		"timeout": "10m",
+		"flag":    "",
+	}
+	if diff := cmp.Diff(golden, v); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// squashDirective is the name of the header directive that marks a snapshot
+// file, i.e. `-- +squash 100_users.cql`.
+const squashDirective = "squash"
+
+// sequence is a namespace of migrations resolved against the database.
+type sequence struct {
+	namespace string
+	// dbm are database records of migrations in the sequence.
+	dbm []*Info
+	// fm are migrations to apply and validate.
+	fm []migration
+	// skipped are migrations replaced by a snapshot, or a snapshot of
+	// migrations already recorded in the database.
+	skipped []migration
+	// squashed are database records of migrations replaced by a snapshot
+	// that no longer have files.
+	squashed []*Info
+}
+
+// sequences groups migrations and database records by namespace and
+// resolves squash snapshots. Namespaces are ordered as in listMigrations,
+// followed by namespaces found only in the database.
+func (mg *Migrator) sequences(f fs.FS, dbm []*Info) ([]*sequence, error) {
+	fm, err := listMigrations(f, mg.Namespaced)
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+
+	var v []*sequence
+	ns := make(map[string]*sequence)
+	get := func(name string) *sequence {
+		s, ok := ns[name]
+		if !ok {
+			s = &sequence{namespace: name}
+			ns[name] = s
+			v = append(v, s)
+		}
+		return s
+	}
+	for _, m := range fm {
+		s := get(namespace(m.name))
+		s.fm = append(s.fm, m)
+	}
+	for _, info := range dbm {
+		name := namespace(info.Name)
+		if name != "" && !mg.Namespaced {
+			continue
+		}
+		s := get(name)
+		s.dbm = append(s.dbm, info)
+	}
+
+	for _, s := range v {
+		if err := s.squash(f); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+// squash resolves a snapshot file in the sequence. A snapshot replaces all
+// migrations that sort before or equal to the migration named in the squash
+// directive. For a new database the replaced migrations are skipped and the
+// snapshot is applied instead. For a database that already has the replaced
+// migrations the snapshot is skipped, migrations with files are validated
+// as usual and records without files are accepted as squashed.
+func (s *sequence) squash(f fs.FS) error {
+	var (
+		snapshot migration
+		last     string
+	)
+	for _, m := range s.fm {
+		if m.fn != nil {
+			continue
+		}
+		b, err := fs.ReadFile(f, m.name)
+		if err != nil {
+			return fmt.Errorf("read %q: %w", m.name, err)
+		}
+		d, err := directives(b)
+		if err != nil {
+			return fmt.Errorf("read %q: %w", m.name, err)
+		}
+		v, ok := d[squashDirective]
+		if !ok {
+			continue
+		}
+		if v == "" {
+			return fmt.Errorf("snapshot %q: missing name of the last replaced migration", m.name)
+		}
+		if snapshot.name != "" {
+			return fmt.Errorf("multiple snapshots found, %q and %q", snapshot.name, m.name)
+		}
+		snapshot, last = m, path.Join(s.namespace, v)
+	}
+	if snapshot.name == "" {
+		return nil
+	}
+
+	covered := func(name string) bool {
+		return name <= last && name != snapshot.name
+	}
+
+	applied := false
+	files := make(map[string]struct{}, len(s.fm))
+	for _, m := range s.fm {
+		files[m.name] = struct{}{}
+	}
+	for _, info := range s.dbm {
+		if info.Name == snapshot.name {
+			applied = true
+		}
+	}
+
+	// existing database, keep the history and skip the snapshot
+	if !applied && len(s.dbm) > 0 && covered(s.dbm[0].Name) {
+		var (
+			dbm              []*Info
+			reached, missing bool
+		)
+		for _, info := range s.dbm {
+			if info.Name == last {
+				reached = true
+			}
+			if _, ok := files[info.Name]; !ok && covered(info.Name) {
+				s.squashed = append(s.squashed, info)
+				missing = true
+				continue
+			}
+			dbm = append(dbm, info)
+		}
+		if missing && !reached {
+			return fmt.Errorf("snapshot %q replaces migrations not applied to the database, apply migrations up to %q first", snapshot.name, last)
+		}
+		s.dbm = dbm
+
+		fm := s.fm[:0:0]
+		for _, m := range s.fm {
+			if m.name == snapshot.name {
+				s.skipped = append(s.skipped, m)
+				continue
+			}
+			fm = append(fm, m)
+		}
+		s.fm = fm
+		return nil
+	}
+
+	// new database, apply the snapshot instead of the replaced migrations
+	fm := s.fm[:0:0]
+	for _, m := range s.fm {
+		if covered(m.name) {
+			s.skipped = append(s.skipped, m)
+			continue
+		}
+		fm = append(fm, m)
+	}
+	if fm[0].name != snapshot.name {
+		return fmt.Errorf("snapshot %q must sort before %q", snapshot.name, fm[0].name)
+	}
+	s.fm = fm
+
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/psanford/memfs"
+)
+
+func TestSequencesSquash(t *testing.T) {
+	table := []struct {
+		Name     string
+		Files    []string
+		Applied  []string
+		FM       []string
+		DBM      []string
+		Skipped  []string
+		Squashed []string
+		Err      string
+	}{
+		{
+			Name:  "no snapshot",
+			Files: []string{"0.cql", "1.cql"},
+			FM:    []string{"0.cql", "1.cql"},
+		},
+		{
+			Name:    "new database",
+			Files:   []string{"0.cql", "1.cql", "1_snapshot.cql", "2.cql"},
+			FM:      []string{"1_snapshot.cql", "2.cql"},
+			Skipped: []string{"0.cql", "1.cql"},
+		},
+		{
+			Name:  "new database without replaced files",
+			Files: []string{"1_snapshot.cql", "2.cql"},
+			FM:    []string{"1_snapshot.cql", "2.cql"},
+		},
+		{
+			Name:    "snapshot applied",
+			Files:   []string{"0.cql", "1.cql", "1_snapshot.cql", "2.cql"},
+			Applied: []string{"1_snapshot.cql"},
+			FM:      []string{"1_snapshot.cql", "2.cql"},
+			DBM:     []string{"1_snapshot.cql"},
+			Skipped: []string{"0.cql", "1.cql"},
+		},
+		{
+			Name:    "existing database",
+			Files:   []string{"0.cql", "1.cql", "1_snapshot.cql", "2.cql"},
+			Applied: []string{"0.cql", "1.cql"},
+			FM:      []string{"0.cql", "1.cql", "2.cql"},
+			DBM:     []string{"0.cql", "1.cql"},
+			Skipped: []string{"1_snapshot.cql"},
+		},
+		{
+			Name:     "existing database without replaced files",
+			Files:    []string{"1_snapshot.cql", "2.cql"},
+			Applied:  []string{"0.cql", "1.cql", "2.cql"},
+			FM:       []string{"2.cql"},
+			DBM:      []string{"2.cql"},
+			Skipped:  []string{"1_snapshot.cql"},
+			Squashed: []string{"0.cql", "1.cql"},
+		},
+		{
+			Name:    "existing database behind snapshot",
+			Files:   []string{"1_snapshot.cql", "2.cql"},
+			Applied: []string{"0.cql"},
+			Err:     "apply migrations up to \"1.cql\" first",
+		},
+		{
+			Name:  "snapshot sorts after migration",
+			Files: []string{"0.cql", "1.cql", "2.cql", "3_snapshot.cql"},
+			Err:   "snapshot \"3_snapshot.cql\" must sort before \"2.cql\"",
+		},
+		{
+			Name:  "multiple snapshots",
+			Files: []string{"1_snapshot.cql", "2_snapshot.cql"},
+			Err:   "multiple snapshots found",
+		},
+	}
+
+	for i := range table {
+		test := table[i]
+		t.Run(test.Name, func(t *testing.T) {
+			f := memfs.New()
+			for _, name := range test.Files {
+				data := "CREATE TABLE foo (id int PRIMARY KEY);"
+				switch {
+				case strings.HasPrefix(name, "1_snapshot"), strings.HasPrefix(name, "3_snapshot"):
+					data = "-- Snapshot\n-- +squash 1.cql\n" + data
+				case strings.HasPrefix(name, "2_snapshot"):
+					data = "-- +squash 2.cql\n" + data
+				}
+				if err := f.WriteFile(name, []byte(data), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var dbm []*Info
+			for _, name := range test.Applied {
+				dbm = append(dbm, &Info{Name: name, Done: 1})
+			}
+
+			v, err := (&Migrator{}).sequences(f, dbm)
+			if test.Err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.Err) {
+					t.Fatalf("sequences() error %v, expected %q", err, test.Err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(v) != 1 {
+				t.Fatalf("sequences() got %d sequences, expected 1", len(v))
+			}
+
+			var fm, dbmNames, skipped, squashed []string
+			for _, m := range v[0].fm {
+				fm = append(fm, m.name)
+			}
+			for _, info := range v[0].dbm {
+				dbmNames = append(dbmNames, info.Name)
+			}
+			for _, m := range v[0].skipped {
+				skipped = append(skipped, m.name)
+			}
+			for _, info := range v[0].squashed {
+				squashed = append(squashed, info.Name)
+			}
+			if diff := cmp.Diff(test.FM, fm); diff != "" {
+				t.Error("fm", diff)
+			}
+			if diff := cmp.Diff(test.DBM, dbmNames); diff != "" {
+				t.Error("dbm", diff)
+			}
+			if diff := cmp.Diff(test.Skipped, skipped); diff != "" {
+				t.Error("skipped", diff)
+			}
+			if diff := cmp.Diff(test.Squashed, squashed); diff != "" {
+				t.Error("squashed", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+
+	"github.com/scylladb/gocqlx/v3"
+)
+
+// MigrationStatus describes a migration and its progress in the database.
+type MigrationStatus struct {
+	// Applied is the database record of the migration, nil if the migration
+	// was not started.
+	Applied *Info
+	Name    string
+	// Checksum of the migration calculated with the algorithm of the database
+	// record, empty if the migration is recorded in the database but there
+	// is no such file or Go code migration.
+	Checksum string
+	// Statements of a CQL file, callbacks are listed as `-- CALL <name>;`.
+	Statements []string
+	// Func is set for Go code migrations.
+	Func bool
+	// Squashed is set for migrations replaced by a squash snapshot, and for
+	// a snapshot that is not applied because the database already has
+	// the migrations it replaces.
+	Squashed bool
+}
+
+// Steps returns number of statements to apply, it's 1 for Go code migrations.
+func (s *MigrationStatus) Steps() int {
+	if s.Func {
+		return 1
+	}
+	return len(s.Statements)
+}
+
+// Done returns number of applied statements.
+func (s *MigrationStatus) Done() int {
+	if s.Applied == nil {
+		return 0
+	}
+	return s.Applied.Done
+}
+
+// Pending returns true if the migration is not started or partially applied.
+func (s *MigrationStatus) Pending() bool {
+	return s.Checksum != "" && !s.Squashed && s.Done() < s.Steps()
+}
+
+// Status lists file and Go code migrations along with their progress in
+// the database. Migrations found only in the database are listed as well.
+func Status(ctx context.Context, session gocqlx.Session, f fs.FS) ([]*MigrationStatus, error) {
+	return (&Migrator{}).Status(ctx, session, f)
+}
+
+// Status lists file and Go code migrations along with their progress in
+// the database, see the Status function for details.
+func (mg *Migrator) Status(ctx context.Context, session gocqlx.Session, f fs.FS) ([]*MigrationStatus, error) {
+	f = mg.renderFS(f)
+
+	dbm, err := mg.List(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+	applied := make(map[string]*Info, len(dbm))
+	for _, info := range dbm {
+		applied[info.Name] = info
+	}
+
+	seqs, err := mg.sequences(f, dbm)
+	if err != nil {
+		return nil, err
+	}
+
+	v := make([]*MigrationStatus, 0, len(dbm))
+	for _, seq := range seqs {
+		for _, m := range seq.fm {
+			st, err := migrationStatus(f, m, applied[m.name])
+			if err != nil {
+				return nil, err
+			}
+			v = append(v, st)
+			delete(applied, m.name)
+		}
+		for _, m := range seq.skipped {
+			st, err := migrationStatus(f, m, applied[m.name])
+			if err != nil {
+				return nil, err
+			}
+			st.Squashed = true
+			v = append(v, st)
+			delete(applied, m.name)
+		}
+		for _, info := range seq.squashed {
+			v = append(v, &MigrationStatus{
+				Applied:  info,
+				Name:     info.Name,
+				Squashed: true,
+			})
+			delete(applied, info.Name)
+		}
+	}
+	for _, info := range applied {
+		v = append(v, &MigrationStatus{
+			Applied: info,
+			Name:    info.Name,
+		})
+	}
+
+	sort.Slice(v, func(i, j int) bool {
+		return v[i].Name < v[j].Name
+	})
+
+	return v, nil
+}
+
+func migrationStatus(f fs.FS, m migration, info *Info) (*MigrationStatus, error) {
+	alg := DefaultChecksumAlgorithm
+	if info != nil {
+		alg = checksumAlgorithm(info.Checksum)
+	}
+	c, err := m.checksum(f, alg)
+	if err != nil {
+		return nil, fmt.Errorf("calculate checksum for %q: %w", m.name, err)
+	}
+	stmts, err := m.statements(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %q: %w", m.name, err)
+	}
+
+	s := &MigrationStatus{
+		Applied:  info,
+		Name:     m.name,
+		Checksum: c,
+		Func:     m.fn != nil,
+	}
+	for _, stmt := range stmts {
+		if stmt.callback != "" {
+			s.Statements = append(s.Statements, "-- CALL "+stmt.callback+";")
+		} else {
+			s.Statements = append(s.Statements, stmt.text)
+		}
+	}
+	return s, nil
+}
+
+// MarkApplied records the migration as applied without executing it.
+// The current checksum of the migration is recorded, so it can be used
+// to accept changes to an already applied file that FromFS reports as
+// tampered with, or to skip a migration that was applied manually.
+func MarkApplied(ctx context.Context, session gocqlx.Session, f fs.FS, name string) error {
+	return (&Migrator{}).MarkApplied(ctx, session, f, name)
+}
+
+// MarkApplied records the migration as applied without executing it,
+// see the MarkApplied function for details.
+func (mg *Migrator) MarkApplied(ctx context.Context, session gocqlx.Session, f fs.FS, name string) error {
+	f = mg.renderFS(f)
+
+	if err := mg.ensureInfoTable(ctx, session); err != nil {
+		return err
+	}
+
+	fm, err := listMigrations(f, mg.Namespaced)
+	if err != nil {
+		return fmt.Errorf("list migrations: %w", err)
+	}
+	i := slices.IndexFunc(fm, func(m migration) bool {
+		return m.name == name
+	})
+	if i < 0 {
+		return fmt.Errorf("migration %q not found", name)
+	}
+	return mg.markApplied(ctx, session, f, fm[i])
+}
+
+// Baseline records all migrations up to and including the named migration
+// as applied without executing them. It's meant for adopting migrations on
+// a database that already has the schema, i.e. one provisioned before
+// the migrations were introduced or restored from a backup.
+// Only migrations in the namespace of the named migration are recorded,
+// migrations already recorded in the database are left unchanged.
+//
+// If a squash snapshot replaces the named migration on a new database,
+// the snapshot has to be named instead.
+func Baseline(ctx context.Context, session gocqlx.Session, f fs.FS, name string) error {
+	return (&Migrator{}).Baseline(ctx, session, f, name)
+}
+
+// Baseline records all migrations up to and including the named migration
+// as applied without executing them, see the Baseline function for details.
+func (mg *Migrator) Baseline(ctx context.Context, session gocqlx.Session, f fs.FS, name string) error {
+	f = mg.renderFS(f)
+
+	if err := mg.ensureInfoTable(ctx, session); err != nil {
+		return err
+	}
+
+	dbm, err := mg.List(ctx, session)
+	if err != nil {
+		return fmt.Errorf("list migrations: %w", err)
+	}
+	seqs, err := mg.sequences(f, dbm)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(seqs, func(seq *sequence) bool {
+		return seq.namespace == namespace(name)
+	})
+	if i < 0 {
+		return fmt.Errorf("migration %q not found", name)
+	}
+	seq := seqs[i]
+
+	if slices.ContainsFunc(seq.skipped, func(m migration) bool { return m.name == name }) {
+		return fmt.Errorf("migration %q is squashed", name)
+	}
+	n := slices.IndexFunc(seq.fm, func(m migration) bool {
+		return m.name == name
+	})
+	if n < 0 {
+		return fmt.Errorf("migration %q not found", name)
+	}
+
+	applied := make(map[string]struct{}, len(seq.dbm))
+	for _, info := range seq.dbm {
+		applied[info.Name] = struct{}{}
+	}
+	for _, m := range seq.fm[:n+1] {
+		if _, ok := applied[m.name]; ok {
+			continue
+		}
+		if err := mg.markApplied(ctx, session, f, m); err != nil {
+			return fmt.Errorf("mark %q applied: %w", m.name, err)
+		}
+	}
+
+	return nil
+}
+
+// markApplied records the migration as complete with the current checksum.
+func (mg *Migrator) markApplied(ctx context.Context, session gocqlx.Session, f fs.FS, m migration) error {
+	c, err := m.checksum(f, DefaultChecksumAlgorithm)
+	if err != nil {
+		return fmt.Errorf("calculate checksum for %q: %w", m.name, err)
+	}
+	n, err := m.steps(f)
+	if err != nil {
+		return fmt.Errorf("read %q: %w", m.name, err)
+	}
+
+	info := newInfo(m.name, c)
+	info.Done = n
+	info.EndTime = info.StartTime
+	return mg.updateInfoQuery(ctx, session).BindStruct(info).ExecRelease()
+}
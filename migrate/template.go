@@ -0,0 +1,113 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// templateFS renders *.cql files with text/template. Every file is rendered
+// once, later opens serve the rendered bytes.
+type templateFS struct {
+	fs.FS
+	data  interface{}
+	funcs template.FuncMap
+
+	mu       sync.Mutex
+	rendered map[string]rendered
+}
+
+// rendered is a rendered template along with file info of the template.
+type rendered struct {
+	b  []byte
+	fi fs.FileInfo
+}
+
+// renderFS returns f wrapped to render migration files if TemplateData is set.
+func (mg *Migrator) renderFS(f fs.FS) fs.FS {
+	if mg.TemplateData == nil {
+		return f
+	}
+	return &templateFS{FS: f, data: mg.TemplateData, funcs: mg.TemplateFuncs}
+}
+
+func (t *templateFS) Open(name string) (fs.File, error) {
+	if !strings.HasSuffix(name, ".cql") {
+		return t.FS.Open(name)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.rendered[name]
+	if !ok {
+		var err error
+		if r, err = t.render(name); err != nil {
+			return nil, err
+		}
+		if t.rendered == nil {
+			t.rendered = make(map[string]rendered)
+		}
+		t.rendered[name] = r
+	}
+
+	return &renderedFile{
+		Reader:   bytes.NewReader(r.b),
+		FileInfo: r.fi,
+	}, nil
+}
+
+func (t *templateFS) render(name string) (rendered, error) {
+	file, err := t.FS.Open(name)
+	if err != nil {
+		return rendered{}, err
+	}
+	defer file.Close()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return rendered{}, err
+	}
+	b, err := io.ReadAll(file)
+	if err != nil {
+		return rendered{}, err
+	}
+
+	tmpl, err := template.New(path.Base(name)).Option("missingkey=error").Funcs(t.funcs).Parse(string(b))
+	if err != nil {
+		return rendered{}, &fs.PathError{Op: "parse template", Path: name, Err: err}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, t.data); err != nil {
+		return rendered{}, &fs.PathError{Op: "render template", Path: name, Err: err}
+	}
+
+	return rendered{b: buf.Bytes(), fi: fi}, nil
+}
+
+// renderedFile is an in-memory file holding rendered template, file info
+// is the info of the template except for the size.
+type renderedFile struct {
+	*bytes.Reader
+	fs.FileInfo
+}
+
+func (f *renderedFile) Stat() (fs.FileInfo, error) {
+	return f, nil
+}
+
+func (f *renderedFile) Size() int64 {
+	return f.Reader.Size()
+}
+
+func (f *renderedFile) Close() error {
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/psanford/memfs"
+)
+
+func TestRenderFS(t *testing.T) {
+	f := memfs.New()
+	files := map[string]string{
+		"0.cql":   "CREATE KEYSPACE {{.Keyspace}} WITH replication = {'class': 'NetworkTopologyStrategy', 'dc1': {{.RF}}};",
+		"1.cql":   "CREATE TABLE {{.Keyspace}}.foo (id int PRIMARY KEY) WITH default_time_to_live = {{ttl .TTL}};",
+		"2.cql":   "SELECT * FROM {{.Missing}};",
+		"3.cql":   "SELECT * FROM {{.Keyspace;",
+		"doc.txt": "{{.Keyspace}}",
+	}
+	for name, text := range files {
+		if err := f.WriteFile(name, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mg := &Migrator{
+		TemplateData: map[string]interface{}{
+			"Keyspace": "ks",
+			"RF":       3,
+			"TTL":      "1h",
+		},
+		TemplateFuncs: template.FuncMap{
+			"ttl": func(s string) int {
+				if s == "1h" {
+					return 3600
+				}
+				return 0
+			},
+		},
+	}
+	r := mg.renderFS(f)
+
+	golden := map[string]string{
+		"0.cql":   "CREATE KEYSPACE ks WITH replication = {'class': 'NetworkTopologyStrategy', 'dc1': 3};",
+		"1.cql":   "CREATE TABLE ks.foo (id int PRIMARY KEY) WITH default_time_to_live = 3600;",
+		"doc.txt": "{{.Keyspace}}",
+	}
+	for name, text := range golden {
+		b, err := fs.ReadFile(r, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != text {
+			t.Fatalf("%s: got %q expected %q", name, b, text)
+		}
+		fi, err := fs.Stat(r, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Size() != int64(len(text)) {
+			t.Fatalf("%s: size %d expected %d", name, fi.Size(), len(text))
+		}
+	}
+
+	for name, msg := range map[string]string{
+		"2.cql": "render template 2.cql",
+		"3.cql": "parse template 3.cql",
+	} {
+		if _, err := fs.ReadFile(r, name); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("%s: error=%v expected %q", name, err, msg)
+		}
+	}
+
+	if (&Migrator{}).renderFS(f) != fs.FS(f) {
+		t.Fatal("expected file system without TemplateData to be used as is")
+	}
+}
+
+// openCounter counts opened files.
+type openCounter struct {
+	fs.FS
+	n map[string]int
+}
+
+func (c openCounter) Open(name string) (fs.File, error) {
+	c.n[name]++
+	return c.FS.Open(name)
+}
+
+func TestRenderFSOnce(t *testing.T) {
+	f := memfs.New()
+	if err := f.WriteFile("0.cql", []byte("SELECT * FROM {{.Keyspace}}.foo;"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := openCounter{FS: f, n: map[string]int{}}
+	r := (&Migrator{TemplateData: map[string]string{"Keyspace": "ks"}}).renderFS(c)
+
+	for i := 0; i < 3; i++ {
+		b, err := fs.ReadFile(r, "0.cql")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "SELECT * FROM ks.foo;" {
+			t.Fatalf("got %q", b)
+		}
+	}
+	if c.n["0.cql"] != 1 {
+		t.Fatalf("0.cql opened %d times expected 1", c.n["0.cql"])
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gocql/gocql"
+
+	"github.com/scylladb/gocqlx/v3"
+)
+
+// Header directives overriding Migrator options for a single file.
+const (
+	timeoutDirective                = "timeout"
+	schemaAgreementTimeoutDirective = "schema-agreement-timeout"
+	retriesDirective                = "retries"
+)
+
+// fileOptions are Migrator options with the file header directives applied.
+type fileOptions struct {
+	statementTimeout       time.Duration
+	schemaAgreementTimeout time.Duration
+	retries                int
+}
+
+// fileOptions returns options for a migration file, the Migrator options can
+// be overridden with `-- +timeout <duration>`, `-- +schema-agreement-timeout
+// <duration>` and `-- +retries <n>` directives in the file header.
+func (mg *Migrator) fileOptions(b []byte) (fileOptions, error) {
+	opts := fileOptions{
+		statementTimeout:       mg.StatementTimeout,
+		schemaAgreementTimeout: mg.SchemaAgreementTimeout,
+		retries:                mg.Retries,
+	}
+
+	d, err := directives(b)
+	if err != nil {
+		return opts, err
+	}
+	for name, p := range map[string]*time.Duration{
+		timeoutDirective:                &opts.statementTimeout,
+		schemaAgreementTimeoutDirective: &opts.schemaAgreementTimeout,
+	} {
+		v, ok := d[name]
+		if !ok {
+			continue
+		}
+		t, err := time.ParseDuration(v)
+		if err != nil || t < 0 {
+			return opts, fmt.Errorf("invalid +%s directive %q", name, v)
+		}
+		*p = t
+	}
+	if v, ok := d[retriesDirective]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf("invalid +%s directive %q", retriesDirective, v)
+		}
+		opts.retries = n
+	}
+
+	return opts, nil
+}
+
+// execStatement executes a CQL statement with the statement timeout.
+// Idempotent statements are retried on timeouts and unavailability errors.
+func execStatement(ctx context.Context, session gocqlx.Session, stmt statement, opts fileOptions) error {
+	attempts := 1
+	if idempotent(stmt.tokens) {
+		attempts += opts.retries
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = execWithTimeout(ctx, session, stmt.text, opts.statementTimeout); err == nil || !retryable(err) {
+			break
+		}
+	}
+	if err != nil && attempts > 1 && retryable(err) {
+		return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+	}
+	return err
+}
+
+func execWithTimeout(ctx context.Context, session gocqlx.Session, stmt string, timeout time.Duration) error {
+	if timeout <= 0 {
+		return session.ContextQuery(ctx, stmt, nil).RetryPolicy(nil).ExecRelease()
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := session.ContextQuery(tctx, stmt, nil).RetryPolicy(nil).ExecRelease()
+	// ctx is not canceled with the migration context, see applyMigration,
+	// so the deadline is always the statement timeout.
+	if err != nil && errors.Is(tctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s: %w", timeout, err)
+	}
+	return err
+}
+
+// idempotent reports whether statement can be safely executed again,
+// i.e. CREATE ... IF NOT EXISTS or DROP ... IF EXISTS.
+func idempotent(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	switch {
+	case tokens[0].isWord("CREATE"):
+		return containsWords(tokens, "IF", "NOT", "EXISTS") || containsWords(tokens, "OR", "REPLACE")
+	case tokens[0].isWord("DROP"):
+		return containsWords(tokens, "IF", "EXISTS")
+	default:
+		return false
+	}
+}
+
+// retryable reports whether err is a timeout or unavailability error.
+func retryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, gocql.ErrTimeoutNoResponse) ||
+		errors.Is(err, gocql.ErrConnectionClosed) ||
+		errors.Is(err, gocql.ErrNoConnections) {
+		return true
+	}
+
+	var reqErr interface{ GetCode() int }
+	if errors.As(err, &reqErr) {
+		switch reqErr.GetCode() {
+		case gocql.ErrCodeUnavailable, gocql.ErrCodeOverloaded, gocql.ErrCodeBootstrapping,
+			gocql.ErrCodeWriteTimeout, gocql.ErrCodeReadTimeout:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+func TestFileOptions(t *testing.T) {
+	mg := Migrator{StatementTimeout: time.Minute, Retries: 1}
+
+	table := []struct {
+		Name   string
+		Input  string
+		Golden fileOptions
+		Err    bool
+	}{
+		{
+			Name:   "defaults",
+			Input:  "SELECT * FROM foo;",
+			Golden: fileOptions{statementTimeout: time.Minute, retries: 1},
+		},
+		{
+			Name:   "override",
+			Input:  "-- +timeout 10m\n-- +schema-agreement-timeout 30s\n-- +retries 0\nSELECT * FROM foo;",
+			Golden: fileOptions{statementTimeout: 10 * time.Minute, schemaAgreementTimeout: 30 * time.Second},
+		},
+		{
+			Name:  "invalid timeout",
+			Input: "-- +timeout -1s\nSELECT * FROM foo;",
+			Err:   true,
+		},
+		{
+			Name:  "invalid retries",
+			Input: "-- +retries many\nSELECT * FROM foo;",
+			Err:   true,
+		},
+	}
+
+	for i := range table {
+		test := table[i]
+		t.Run(test.Name, func(t *testing.T) {
+			v, err := mg.fileOptions([]byte(test.Input))
+			if test.Err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != test.Golden {
+				t.Fatalf("fileOptions()=%+v expected %+v", v, test.Golden)
+			}
+		})
+	}
+}
+
+func TestIdempotent(t *testing.T) {
+	table := []struct {
+		Input  string
+		Golden bool
+	}{
+		{"CREATE TABLE IF NOT EXISTS foo (id int PRIMARY KEY)", true},
+		{"create index if not exists on foo (v)", true},
+		{"CREATE OR REPLACE FUNCTION f (a int) RETURNS NULL ON NULL INPUT RETURNS int LANGUAGE lua AS 'return a'", true},
+		{"DROP TABLE IF EXISTS foo", true},
+		{"CREATE TABLE foo (id int PRIMARY KEY)", false},
+		{"DROP TABLE foo", false},
+		{"INSERT INTO foo (id) VALUES (1) IF NOT EXISTS", false},
+		{"ALTER TABLE foo ADD v text", false},
+	}
+
+	for _, test := range table {
+		stmts, err := splitStatements([]byte(test.Input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := idempotent(stmts[0].tokens); v != test.Golden {
+			t.Errorf("idempotent(%q)=%v expected %v", test.Input, v, test.Golden)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	writeTimeout := &gocql.RequestErrWriteTimeout{}
+	writeTimeout.Code = gocql.ErrCodeWriteTimeout
+	alreadyExists := &gocql.RequestErrAlreadyExists{}
+	alreadyExists.Code = gocql.ErrCodeAlreadyExists
+
+	table := []struct {
+		Err    error
+		Golden bool
+	}{
+		{context.DeadlineExceeded, true},
+		{fmt.Errorf("timed out after 1s: %w", context.DeadlineExceeded), true},
+		{gocql.ErrTimeoutNoResponse, true},
+		{gocql.ErrNoConnections, true},
+		{fmt.Errorf("statement: %w", writeTimeout), true},
+		{alreadyExists, false},
+		{context.Canceled, false},
+		{errors.New("syntax error"), false},
+	}
+
+	for _, test := range table {
+		if v := retryable(test.Err); v != test.Golden {
+			t.Errorf("retryable(%v)=%v expected %v", test.Err, v, test.Golden)
+		}
+	}
+}
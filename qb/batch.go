@@ -77,6 +77,15 @@ func (b *BatchBuilder) QueryContext(ctx context.Context, session gocqlx.Session)
 	return b.Query(session).WithContext(ctx)
 }
 
+// Clone returns a copy of the builder, modifying the copy does not affect
+// the builder. Use it to extend a shared base builder.
+func (b *BatchBuilder) Clone() *BatchBuilder {
+	c := *b
+	c.stmts = append([]string(nil), b.stmts...)
+	c.names = append([]string(nil), b.names...)
+	return &c
+}
+
 // Add builds the builder and adds the statement to the batch.
 func (b *BatchBuilder) Add(builder Builder) *BatchBuilder {
 	return b.AddStmt(builder.ToCql())
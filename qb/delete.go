@@ -18,7 +18,7 @@ import (
 // DeleteBuilder builds CQL DELETE statements.
 type DeleteBuilder struct {
 	table   string
-	columns columns
+	columns valueList
 	where   where
 	_if     _if
 	using   using
@@ -39,7 +39,7 @@ func (b *DeleteBuilder) ToCql() (stmt string, names []string) {
 
 	cql.WriteString("DELETE ")
 	if len(b.columns) > 0 {
-		b.columns.writeCql(&cql)
+		names = append(names, b.columns.writeCql(&cql)...)
 		cql.WriteByte(' ')
 	}
 	cql.WriteString("FROM ")
@@ -68,6 +68,16 @@ func (b *DeleteBuilder) QueryContext(ctx context.Context, session gocqlx.Session
 	return b.Query(session).WithContext(ctx)
 }
 
+// Clone returns a copy of the builder, modifying the copy does not affect
+// the builder. Use it to extend a shared base builder.
+func (b *DeleteBuilder) Clone() *DeleteBuilder {
+	c := *b
+	c.columns = append(valueList(nil), b.columns...)
+	c.where = append(where(nil), b.where...)
+	c._if = append(_if(nil), b._if...)
+	return &c
+}
+
 // From sets the table to be deleted from.
 // See the package documentation for table name quoting rules.
 func (b *DeleteBuilder) From(table string) *DeleteBuilder {
@@ -77,7 +87,29 @@ func (b *DeleteBuilder) From(table string) *DeleteBuilder {
 
 // Columns adds delete columns to the query.
 func (b *DeleteBuilder) Columns(columns ...string) *DeleteBuilder {
-	b.columns = append(b.columns, columns...)
+	for _, c := range columns {
+		b.columns = append(b.columns, lit(c))
+	}
+	return b
+}
+
+// ColumnElem adds column[?] to the query, it deletes a map value or a list
+// element. The key parameter is named column_key, i.e. m_key.
+func (b *DeleteBuilder) ColumnElem(column string) *DeleteBuilder {
+	return b.ColumnElemNamed(column, elemKey(column))
+}
+
+// ColumnElemNamed adds column[?] to the query with a custom key parameter
+// name.
+func (b *DeleteBuilder) ColumnElemNamed(column, key string) *DeleteBuilder {
+	b.columns = append(b.columns, elem{column: column, key: param(key)})
+	return b
+}
+
+// ColumnField adds column.field to the query, it deletes a single field of
+// a UDT.
+func (b *DeleteBuilder) ColumnField(column, field string) *DeleteBuilder {
+	b.columns = append(b.columns, lit(column+"."+field))
 	return b
 }
 
@@ -114,6 +146,15 @@ func (b *DeleteBuilder) Where(w ...Cmp) *DeleteBuilder {
 	return b
 }
 
+// WhereIf adds an expression to the WHERE clause of the query if cond is
+// true, i.e. WhereIf(req.Name != "", Eq("name")).
+func (b *DeleteBuilder) WhereIf(cond bool, w ...Cmp) *DeleteBuilder {
+	if cond {
+		b.Where(w...)
+	}
+	return b
+}
+
 // If adds an expression to the IF clause of the query. Expressions are ANDed
 // together in the generated CQL.
 func (b *DeleteBuilder) If(w ...Cmp) *DeleteBuilder {
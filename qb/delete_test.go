@@ -25,6 +25,12 @@ func TestDeleteBuilder(t *testing.T) {
 			S: "DELETE FROM cycling.cyclist_name WHERE id=? ",
 			N: []string{"expr"},
 		},
+		// Add WHERE conditionally
+		{
+			B: Delete("cycling.cyclist_name").Where(w).WhereIf(false, Eq("firstname")).WhereIf(true, Eq("lastname")),
+			S: "DELETE FROM cycling.cyclist_name WHERE id=? AND lastname=? ",
+			N: []string{"expr", "lastname"},
+		},
 		// Change table name
 		{
 			B: Delete("cycling.cyclist_name").Where(w).From("Foobar"),
@@ -37,6 +43,24 @@ func TestDeleteBuilder(t *testing.T) {
 			S: "DELETE stars FROM cycling.cyclist_name WHERE id=? ",
 			N: []string{"expr"},
 		},
+		// Add column element
+		{
+			B: Delete("cycling.cyclist_name").Where(w).ColumnElem("teams").Columns("stars"),
+			S: "DELETE teams[?],stars FROM cycling.cyclist_name WHERE id=? ",
+			N: []string{"teams_key", "expr"},
+		},
+		// Add column element with a custom name
+		{
+			B: Delete("cycling.cyclist_name").Where(w).ColumnElemNamed("teams", "year"),
+			S: "DELETE teams[?] FROM cycling.cyclist_name WHERE id=? ",
+			N: []string{"year", "expr"},
+		},
+		// Add column field
+		{
+			B: Delete("cycling.cyclist_name").Where(w).ColumnField("address", "city"),
+			S: "DELETE address.city FROM cycling.cyclist_name WHERE id=? ",
+			N: []string{"expr"},
+		},
 		// Add WHERE
 		{
 			B: Delete("cycling.cyclist_name").Where(w, Gt("firstname")),
@@ -0,0 +1,397 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gocql/gocql"
+
+	"github.com/scylladb/gocqlx/v3"
+)
+
+// AntiPatternKind specifies type of AntiPattern.
+type AntiPatternKind string
+
+// Anti-patterns reported by Detector.
+const (
+	// AntiPatternAllowFiltering is a query with ALLOW FILTERING.
+	AntiPatternAllowFiltering AntiPatternKind = "allow filtering"
+	// AntiPatternUnboundedSelect is a SELECT without LIMIT that doesn't
+	// restrict the partition key.
+	AntiPatternUnboundedSelect AntiPatternKind = "unbounded select"
+	// AntiPatternLargeIn is an IN restriction on a partition key column with
+	// more than Detector.MaxIn values.
+	AntiPatternLargeIn AntiPatternKind = "large in"
+	// AntiPatternMultiPartitionBatch is a LOGGED batch spanning more than
+	// Detector.MaxBatchPartitions partitions.
+	AntiPatternMultiPartitionBatch AntiPatternKind = "multi partition batch"
+	// AntiPatternNonIdempotent is a non-idempotent statement marked as
+	// idempotent, it may be applied more than once when retried.
+	AntiPatternNonIdempotent AntiPatternKind = "non idempotent"
+)
+
+// AntiPattern is a problem found by Detector.
+type AntiPattern struct {
+	Kind    AntiPatternKind
+	Stmt    string
+	Message string
+}
+
+func (a AntiPattern) String() string {
+	return fmt.Sprintf("%s: %s: %s", a.Kind, a.Message, strings.TrimSpace(a.Stmt))
+}
+
+// Default Detector thresholds.
+const (
+	DefaultMaxIn              = 20
+	DefaultMaxBatchPartitions = 10
+)
+
+// Detector reports query anti-patterns, it's meant to be enabled in
+// development and staging by setting it as gocqlx.Session Inspector.
+// Queries are checked when executed so that Idempotent and bound values
+// are taken into account. Statements that Parse does not support are
+// ignored.
+//
+// Partition key based checks require Schema, without it SELECT is
+// reported as unbounded only if it has no WHERE clause.
+type Detector struct {
+	// Schema returns schema of a table, see table.SchemaFunc.
+	Schema func(keyspace, name string) (Schema, error)
+	// Report is called with every anti-pattern found, if nil anti-patterns
+	// are logged with the standard logger.
+	Report func(AntiPattern)
+	// MaxIn is the maximal number of IN values on a partition key column,
+	// if zero DefaultMaxIn is used.
+	MaxIn int
+	// MaxBatchPartitions is the maximal number of partitions of a LOGGED
+	// batch, if zero DefaultMaxBatchPartitions is used.
+	MaxBatchPartitions int
+}
+
+// InspectQuery implements gocqlx.Inspector.
+func (d *Detector) InspectQuery(q *gocqlx.Queryx) {
+	d.inspect(q.Statement(), q.Keyspace(), q.Values(), q.IsIdempotent())
+}
+
+func (d *Detector) inspect(stmt, keyspace string, values []interface{}, idempotent bool) {
+	b, err := Parse(stmt)
+	if err != nil {
+		return
+	}
+	s, ok := d.schema(keyspace, b)
+
+	switch b := b.(type) {
+	case *SelectBuilder:
+		if b.allowFiltering {
+			d.report(AntiPatternAllowFiltering, stmt, "query uses ALLOW FILTERING")
+		}
+		if b.limit.value == nil && !bounded(b.where, s, ok) {
+			d.report(AntiPatternUnboundedSelect, stmt, "SELECT without LIMIT does not restrict the partition key")
+		}
+	case *UpdateBuilder:
+		if b.allowFiltering {
+			d.report(AntiPatternAllowFiltering, stmt, "query uses ALLOW FILTERING")
+		}
+	}
+
+	if ok {
+		d.checkIn(stmt, b, s, values)
+	}
+	if idempotent {
+		if reason := nonIdempotent(b, s); reason != "" {
+			d.report(AntiPatternNonIdempotent, stmt, "idempotent query with %s", reason)
+		}
+	}
+}
+
+// InspectBatch implements gocqlx.Inspector.
+func (d *Detector) InspectBatch(b *gocqlx.Batch) {
+	maxPartitions := d.MaxBatchPartitions
+	if maxPartitions == 0 {
+		maxPartitions = DefaultMaxBatchPartitions
+	}
+	if b.Type != gocql.LoggedBatch || len(b.Entries) <= maxPartitions {
+		return
+	}
+
+	partitions := make(map[string]struct{})
+	for _, e := range b.Entries {
+		eb, err := Parse(e.Stmt)
+		if err != nil {
+			return
+		}
+		s, ok := d.schema(b.Keyspace(), eb)
+		if !ok {
+			return
+		}
+		key, ok := partitionKey(eb, s, e.Args)
+		if !ok {
+			return
+		}
+		partitions[key] = struct{}{}
+	}
+	if len(partitions) > maxPartitions {
+		d.report(AntiPatternMultiPartitionBatch, b.Entries[0].Stmt,
+			"LOGGED batch of %d statements spans %d partitions", len(b.Entries), len(partitions))
+	}
+}
+
+func (d *Detector) schema(keyspace string, b Builder) (Schema, bool) {
+	if d.Schema == nil {
+		return Schema{}, false
+	}
+	ks, name := splitKeyspace(keyspace, tableOf(b))
+	s, err := d.Schema(ks, name)
+	if err != nil {
+		return Schema{}, false
+	}
+	return s, true
+}
+
+func (d *Detector) report(kind AntiPatternKind, stmt, format string, args ...interface{}) {
+	a := AntiPattern{
+		Kind:    kind,
+		Stmt:    stmt,
+		Message: fmt.Sprintf(format, args...),
+	}
+	if d.Report != nil {
+		d.Report(a)
+	} else {
+		log.Printf("gocqlx: %s", a)
+	}
+}
+
+// checkIn reports IN restrictions on partition key columns with too many
+// values.
+func (d *Detector) checkIn(stmt string, b Builder, s Schema, values []interface{}) {
+	maxIn := d.MaxIn
+	if maxIn == 0 {
+		maxIn = DefaultMaxIn
+	}
+
+	w, offset := whereOf(b)
+	for _, c := range w {
+		n := len(c.value.writeCql(&bytes.Buffer{}))
+		if columns := relationColumns(c.column); c.op == in && len(columns) == 1 && slices.Contains(s.PartKey, columns[0]) {
+			if count := inCount(c.value, offset, values); count > maxIn {
+				d.report(AntiPatternLargeIn, stmt, "IN on partition key column %q has %d values, maximum is %d", columns[0], count, maxIn)
+			}
+		}
+		offset += n
+	}
+}
+
+// inCount returns number of IN values, a single bind marker is counted as
+// the length of the bound slice.
+func inCount(v value, offset int, values []interface{}) int {
+	var cql bytes.Buffer
+	v.writeCql(&cql)
+	toks, err := lex(cql.String())
+	if err != nil || len(toks) == 0 {
+		return 0
+	}
+
+	if toks[0].kind == tokMarker {
+		if offset >= len(values) || values[offset] == nil {
+			return 0
+		}
+		rv := reflect.ValueOf(values[offset])
+		if k := rv.Kind(); k != reflect.Slice && k != reflect.Array || rv.Type().Elem().Kind() == reflect.Uint8 {
+			return 0
+		}
+		return rv.Len()
+	}
+
+	if !toks[0].is("(") || len(toks) == 2 {
+		return 0
+	}
+	count, depth := 1, 0
+	for _, t := range toks {
+		switch {
+		case t.is("("), t.is("["), t.is("{"):
+			depth++
+		case t.is(")"), t.is("]"), t.is("}"):
+			depth--
+		case t.is(",") && depth == 1:
+			count++
+		}
+	}
+	return count
+}
+
+// bounded reports whether WHERE clause restricts the partition key or token.
+// If schema is not known any WHERE clause is considered bounded.
+func bounded(w where, s Schema, ok bool) bool {
+	if !ok {
+		return len(w) > 0
+	}
+	v := validator{schema: s}
+	r := v.restrictions(w)
+	if r.token {
+		return true
+	}
+	for _, k := range s.PartKey {
+		if op, ok := r.ops[k]; !ok || op != eq && op != in {
+			return false
+		}
+	}
+	return true
+}
+
+// nondeterministicFuncs are functions that return a different value with
+// every call.
+var nondeterministicFuncs = []string{
+	"now", "uuid", "currenttimestamp", "currentdate", "currenttime", "currenttimeuuid",
+}
+
+// nonIdempotent returns the reason why statement is not idempotent or empty
+// string.
+func nonIdempotent(b Builder, s Schema) string {
+	var values []value
+	switch b := b.(type) {
+	case *InsertBuilder:
+		if b.unique {
+			return "lightweight transaction"
+		}
+		for _, c := range b.columns {
+			values = append(values, c.value)
+		}
+	case *UpdateBuilder:
+		if b.exists || len(b._if) > 0 {
+			return "lightweight transaction"
+		}
+		for _, a := range b.assignments {
+			column := columnName(a.column)
+			if a.target == nil && (a.valuePrefix != "" || a.valueSuffix != "") {
+				if slices.Contains(s.Counters, column) {
+					return fmt.Sprintf("counter update of %q", column)
+				}
+				if slices.Contains(s.Lists, column) && !strings.HasSuffix(a.valuePrefix, "-") {
+					return fmt.Sprintf("list append to %q", column)
+				}
+			}
+			values = append(values, a.value)
+		}
+	case *DeleteBuilder:
+		if b.exists || len(b._if) > 0 {
+			return "lightweight transaction"
+		}
+	}
+
+	for _, v := range values {
+		var cql bytes.Buffer
+		v.writeCql(&cql)
+		toks, err := lex(cql.String())
+		if err != nil {
+			continue
+		}
+		for i, t := range toks {
+			if t.kind == tokIdent && i+1 < len(toks) && toks[i+1].is("(") &&
+				slices.Contains(nondeterministicFuncs, strings.ToLower(t.text)) {
+				return fmt.Sprintf("function %s()", strings.ToLower(t.text))
+			}
+		}
+	}
+	return ""
+}
+
+// partitionKey returns the partition key values of a modification statement
+// as a string.
+func partitionKey(b Builder, s Schema, args []interface{}) (string, bool) {
+	type column struct {
+		name   string
+		value  value
+		offset int
+	}
+	var columns []column
+
+	switch b := b.(type) {
+	case *InsertBuilder:
+		offset := 0
+		for _, c := range b.columns {
+			columns = append(columns, column{name: columnName(c.column), value: c.value, offset: offset})
+			offset += len(c.value.writeCql(&bytes.Buffer{}))
+		}
+	case *UpdateBuilder, *DeleteBuilder:
+		w, offset := whereOf(b)
+		for _, c := range w {
+			if cols := relationColumns(c.column); c.op == eq && len(cols) == 1 {
+				columns = append(columns, column{name: cols[0], value: c.value, offset: offset})
+			}
+			offset += len(c.value.writeCql(&bytes.Buffer{}))
+		}
+	default:
+		return "", false
+	}
+
+	var key bytes.Buffer
+	key.WriteString(tableOf(b))
+	for _, k := range s.PartKey {
+		i := slices.IndexFunc(columns, func(c column) bool { return c.name == k })
+		if i < 0 {
+			return "", false
+		}
+		c := columns[i]
+		key.WriteByte(0)
+		if _, ok := c.value.(param); ok && c.offset < len(args) {
+			fmt.Fprintf(&key, "%v", args[c.offset])
+		} else {
+			c.value.writeCql(&key)
+		}
+	}
+	return key.String(), true
+}
+
+// whereOf returns WHERE clause of a builder and the number of bind markers
+// preceding it.
+func whereOf(b Builder) (where, int) {
+	var cql bytes.Buffer
+	switch b := b.(type) {
+	case *SelectBuilder:
+		return b.where, len(b.columns.writeCql(&cql))
+	case *UpdateBuilder:
+		n := len(b.using.writeCql(&cql))
+		for _, a := range b.assignments {
+			n += len(a.writeCql(&cql))
+		}
+		return b.where, n
+	case *DeleteBuilder:
+		return b.where, len(b.columns.writeCql(&cql)) + len(b.using.writeCql(&cql))
+	default:
+		return nil, 0
+	}
+}
+
+func tableOf(b Builder) string {
+	switch b := b.(type) {
+	case *SelectBuilder:
+		return b.table
+	case *InsertBuilder:
+		return b.table
+	case *UpdateBuilder:
+		return b.table
+	case *DeleteBuilder:
+		return b.table
+	default:
+		return ""
+	}
+}
+
+// splitKeyspace returns keyspace and name of a possibly keyspace-qualified
+// table name.
+func splitKeyspace(keyspace, table string) (string, string) {
+	parts := splitTableName(table)
+	if len(parts) == 2 {
+		return columnName(parts[0]), columnName(parts[1])
+	}
+	return keyspace, columnName(table)
+}
@@ -0,0 +1,231 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/scylladb/gocqlx/v3"
+)
+
+func testDetector(found *[]AntiPattern) *Detector {
+	schemas := map[string]Schema{
+		"ks.users": {
+			Columns: []string{"id", "bucket", "ts", "name", "tags", "emails"},
+			PartKey: []string{"id", "bucket"},
+			SortKey: []string{"ts"},
+			Lists:   []string{"tags"},
+		},
+		"ks.stats": {
+			Columns:  []string{"id", "views"},
+			PartKey:  []string{"id"},
+			Counters: []string{"views"},
+		},
+	}
+	return &Detector{
+		Schema: func(keyspace, name string) (Schema, error) {
+			s, ok := schemas[keyspace+"."+name]
+			if !ok {
+				return Schema{}, errors.New("not found")
+			}
+			return s, nil
+		},
+		Report: func(a AntiPattern) {
+			*found = append(*found, a)
+		},
+		MaxIn:              3,
+		MaxBatchPartitions: 2,
+	}
+}
+
+func TestDetectorInspect(t *testing.T) {
+	table := []struct {
+		Name       string
+		Keyspace   string
+		Stmt       string
+		Values     []interface{}
+		Idempotent bool
+		Kinds      []AntiPatternKind
+	}{
+		{
+			Name:     "select by partition key",
+			Keyspace: "ks",
+			Stmt:     "SELECT * FROM users WHERE id=? AND bucket IN ? AND ts>? ",
+			Values:   []interface{}{1, []int{1, 2, 3}, 0},
+		},
+		{
+			Name:     "allow filtering",
+			Keyspace: "ks",
+			Stmt:     "SELECT * FROM users WHERE name=? ALLOW FILTERING ",
+			Kinds:    []AntiPatternKind{AntiPatternAllowFiltering, AntiPatternUnboundedSelect},
+		},
+		{
+			Name:     "unbounded select",
+			Keyspace: "ks",
+			Stmt:     "SELECT * FROM users WHERE id=? ",
+			Kinds:    []AntiPatternKind{AntiPatternUnboundedSelect},
+		},
+		{
+			Name:     "select with limit",
+			Keyspace: "ks",
+			Stmt:     "SELECT * FROM users LIMIT 10 ",
+		},
+		{
+			Name:     "token range",
+			Keyspace: "ks",
+			Stmt:     "SELECT * FROM users WHERE token(id,bucket)>? ",
+		},
+		{
+			Name:  "unbounded select without schema",
+			Stmt:  "SELECT * FROM other ",
+			Kinds: []AntiPatternKind{AntiPatternUnboundedSelect},
+		},
+		{
+			Name: "select without schema",
+			Stmt: "SELECT * FROM other WHERE x=? ",
+		},
+		{
+			Name:   "large in bound slice",
+			Stmt:   "SELECT * FROM ks.users WHERE id IN ? AND bucket=? ",
+			Values: []interface{}{[]string{"a", "b", "c", "d"}, 1},
+			Kinds:  []AntiPatternKind{AntiPatternLargeIn},
+		},
+		{
+			Name:   "large in expanded",
+			Stmt:   "SELECT name FROM ks.users WHERE id=? AND bucket IN (?,?,?,?) ",
+			Values: []interface{}{1, 1, 2, 3, 4},
+			Kinds:  []AntiPatternKind{AntiPatternLargeIn},
+		},
+		{
+			Name:   "large in on clustering column",
+			Stmt:   "SELECT * FROM ks.users WHERE id=? AND bucket=? AND ts IN (1,2,3,4) ",
+			Values: []interface{}{1, 1},
+		},
+		{
+			Name:       "idempotent insert",
+			Keyspace:   "ks",
+			Stmt:       "INSERT INTO users (id,bucket,ts,name) VALUES (?,?,?,?) ",
+			Idempotent: true,
+		},
+		{
+			Name:       "idempotent insert with now",
+			Keyspace:   "ks",
+			Stmt:       "INSERT INTO users (id,bucket,ts,name) VALUES (?,?,now(),?) ",
+			Idempotent: true,
+			Kinds:      []AntiPatternKind{AntiPatternNonIdempotent},
+		},
+		{
+			Name: "insert with now",
+			Stmt: "INSERT INTO users (id,bucket,ts,name) VALUES (?,?,now(),?) ",
+		},
+		{
+			Name:       "idempotent lwt",
+			Stmt:       "UPDATE ks.users SET name=? WHERE id=? AND bucket=? AND ts=? IF EXISTS ",
+			Idempotent: true,
+			Kinds:      []AntiPatternKind{AntiPatternNonIdempotent},
+		},
+		{
+			Name:       "idempotent counter",
+			Stmt:       "UPDATE ks.stats SET views=views+? WHERE id=? ",
+			Idempotent: true,
+			Kinds:      []AntiPatternKind{AntiPatternNonIdempotent},
+		},
+		{
+			Name:       "idempotent list append",
+			Stmt:       "UPDATE ks.users SET tags=?+tags WHERE id=? AND bucket=? AND ts=? ",
+			Idempotent: true,
+			Kinds:      []AntiPatternKind{AntiPatternNonIdempotent},
+		},
+		{
+			Name:       "idempotent list remove",
+			Stmt:       "UPDATE ks.users SET tags=tags-? WHERE id=? AND bucket=? AND ts=? ",
+			Idempotent: true,
+		},
+		{
+			Name:       "idempotent set add",
+			Stmt:       "UPDATE ks.users SET emails=emails+? WHERE id=? AND bucket=? AND ts=? ",
+			Idempotent: true,
+		},
+		{
+			Name:       "not supported",
+			Stmt:       "TRUNCATE users",
+			Idempotent: true,
+		},
+	}
+
+	for i := range table {
+		test := table[i]
+		t.Run(test.Name, func(t *testing.T) {
+			var found []AntiPattern
+			testDetector(&found).inspect(test.Stmt, test.Keyspace, test.Values, test.Idempotent)
+
+			var kinds []AntiPatternKind
+			for _, a := range found {
+				if a.Stmt != test.Stmt {
+					t.Errorf("Stmt=%q expected %q", a.Stmt, test.Stmt)
+				}
+				kinds = append(kinds, a.Kind)
+			}
+			if diff := cmp.Diff(test.Kinds, kinds); diff != "" {
+				t.Error(diff, found)
+			}
+		})
+	}
+}
+
+func TestDetectorInspectBatch(t *testing.T) {
+	batch := func(typ gocql.BatchType, ids ...int) *gocqlx.Batch {
+		b := &gocqlx.Batch{Batch: &gocql.Batch{Type: typ}}
+		for _, id := range ids {
+			b.Query("INSERT INTO ks.users (id,bucket,ts,name) VALUES (?,?,?,?) ", id, 1, 0, "a")
+			b.Query("UPDATE ks.users SET name=? WHERE id=? AND bucket=1 AND ts=? ", "b", id, 0)
+		}
+		return b
+	}
+
+	table := []struct {
+		Name  string
+		Batch *gocqlx.Batch
+		Found int
+	}{
+		{
+			Name:  "single partition",
+			Batch: batch(gocql.LoggedBatch, 1, 1),
+		},
+		{
+			Name:  "two partitions",
+			Batch: batch(gocql.LoggedBatch, 1, 2),
+		},
+		{
+			Name:  "many partitions",
+			Batch: batch(gocql.LoggedBatch, 1, 2, 3),
+			Found: 1,
+		},
+		{
+			Name:  "unlogged",
+			Batch: batch(gocql.UnloggedBatch, 1, 2, 3),
+		},
+	}
+
+	for i := range table {
+		test := table[i]
+		t.Run(test.Name, func(t *testing.T) {
+			var found []AntiPattern
+			testDetector(&found).InspectBatch(test.Batch)
+			if len(found) != test.Found {
+				t.Fatalf("found %v expected %d", found, test.Found)
+			}
+			for _, a := range found {
+				if a.Kind != AntiPatternMultiPartitionBatch {
+					t.Fatalf("Kind=%s", a.Kind)
+				}
+			}
+		})
+	}
+}
@@ -12,4 +12,13 @@
 // names: Foobar is rendered as "Foobar" instead of Foobar, preserving case
 // rather than relying on CQL's lowercase folding. Pass lowercase names when
 // targeting lowercase tables.
+//
+// Builders are mutable, use Clone to extend a shared base builder. WHERE
+// clauses can be composed conditionally with WhereIf and from reusable
+// Filter groups.
+//
+// Existing CQL statements can be turned into builders with Parse and then
+// modified like any other builder. Validate and ValidateCql check statements
+// against a table Schema without a cluster, i.e. in unit tests. Detector
+// reports anti-patterns of queries executed by a gocqlx.Session.
 package qb
@@ -0,0 +1,34 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+// Filter is a reusable group of comparators, i.e. a tenant or a time range
+// restriction shared by many queries. It can be added to the WHERE or IF
+// clause of a builder with Where(f...) or If(f...).
+//
+// Filter methods never modify the receiver, so a Filter can be safely
+// shared between goroutines and extended per request.
+type Filter []Cmp
+
+// NewFilter returns a Filter with the given comparators.
+func NewFilter(cmps ...Cmp) Filter {
+	return Filter(nil).And(cmps...)
+}
+
+// And returns a copy of the Filter with the given comparators appended.
+func (f Filter) And(cmps ...Cmp) Filter {
+	v := make(Filter, 0, len(f)+len(cmps))
+	v = append(v, f...)
+	return append(v, cmps...)
+}
+
+// AndIf returns a copy of the Filter with the given comparators appended
+// if cond is true, otherwise it returns the Filter.
+func (f Filter) AndIf(cond bool, cmps ...Cmp) Filter {
+	if !cond {
+		return f
+	}
+	return f.And(cmps...)
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilter(t *testing.T) {
+	tenant := NewFilter(Eq("tenant"))
+	base := make(Filter, 0, 10).And(Eq("tenant"))
+
+	table := []struct {
+		B Builder
+		N []string
+		S string
+	}{
+		{
+			B: Select("users").Where(tenant...),
+			S: "SELECT * FROM users WHERE tenant=? ",
+			N: []string{"tenant"},
+		},
+		{
+			B: Select("users").Where(tenant.And(Eq("id"))...),
+			S: "SELECT * FROM users WHERE tenant=? AND id=? ",
+			N: []string{"tenant", "id"},
+		},
+		{
+			B: Select("users").Where(tenant.AndIf(false, Eq("id")).AndIf(true, Gt("age"))...),
+			S: "SELECT * FROM users WHERE tenant=? AND age>? ",
+			N: []string{"tenant", "age"},
+		},
+		{
+			B: Update("users").Set("name").Where(tenant...).Where(Eq("id")),
+			S: "UPDATE users SET name=? WHERE tenant=? AND id=? ",
+			N: []string{"name", "tenant", "id"},
+		},
+		{
+			B: Delete("users").Where(tenant...).If(tenant.And(Eq("name"))...),
+			S: "DELETE FROM users WHERE tenant=? IF tenant=? AND name=? ",
+			N: []string{"tenant", "tenant", "name"},
+		},
+		// Extending a Filter with spare capacity does not overwrite it
+		{
+			B: Select("users").Where(base...).Where(Eq("id")),
+			S: "SELECT * FROM users WHERE tenant=? AND id=? ",
+			N: []string{"tenant", "id"},
+		},
+		{
+			B: Select("users").Where(base...).Where(Eq("name")),
+			S: "SELECT * FROM users WHERE tenant=? AND name=? ",
+			N: []string{"tenant", "name"},
+		},
+	}
+
+	for _, test := range table {
+		stmt, names := test.B.ToCql()
+		if diff := cmp.Diff(test.S, stmt); diff != "" {
+			t.Error(diff)
+		}
+		if diff := cmp.Diff(test.N, names); diff != "" {
+			t.Error(diff)
+		}
+	}
+
+	if diff := cmp.Diff(NewFilter(Eq("tenant")), tenant, cmp.AllowUnexported(Cmp{})); diff != "" {
+		t.Error("filter modified", diff)
+	}
+}
+
+func TestClone(t *testing.T) {
+	sel := Select("users").Columns("id").Where(Eq("tenant"))
+	upd := Update("users").Set("name").Where(Eq("id")).If(Eq("version"))
+	del := Delete("users").Columns("name").Where(Eq("id")).If(Eq("version"))
+	ins := Insert("users").Columns("id")
+	bat := Batch().Add(ins)
+
+	table := []struct {
+		Base  Builder
+		Clone Builder
+		S     string
+	}{
+		{
+			Base:  sel,
+			Clone: sel.Clone().Columns("name").Where(Eq("id")).OrderBy("id", ASC).GroupBy("tenant").Distinct("tenant"),
+			S:     "SELECT id FROM users WHERE tenant=? ",
+		},
+		{
+			Base:  upd,
+			Clone: upd.Clone().Set("age").Where(Eq("tenant")).If(Eq("age")),
+			S:     "UPDATE users SET name=? WHERE id=? IF version=? ",
+		},
+		{
+			Base:  del,
+			Clone: del.Clone().Columns("age").Where(Eq("tenant")).If(Eq("age")),
+			S:     "DELETE name FROM users WHERE id=? IF version=? ",
+		},
+		{
+			Base:  ins,
+			Clone: ins.Clone().Columns("name"),
+			S:     "INSERT INTO users (id) VALUES (?) ",
+		},
+		{
+			Base:  bat,
+			Clone: bat.Clone().Add(ins),
+			S:     "BEGIN BATCH INSERT INTO users (id) VALUES (?) ; APPLY BATCH ",
+		},
+	}
+
+	for _, test := range table {
+		// Clones must be modified before the base is built
+		clone, _ := test.Clone.ToCql()
+		stmt, _ := test.Base.ToCql()
+		if diff := cmp.Diff(test.S, stmt); diff != "" {
+			t.Error(diff)
+		}
+		if clone == stmt {
+			t.Errorf("clone not modified %q", clone)
+		}
+	}
+}
@@ -92,6 +92,14 @@ func (b *InsertBuilder) QueryContext(ctx context.Context, session gocqlx.Session
 	return b.Query(session).WithContext(ctx)
 }
 
+// Clone returns a copy of the builder, modifying the copy does not affect
+// the builder. Use it to extend a shared base builder.
+func (b *InsertBuilder) Clone() *InsertBuilder {
+	c := *b
+	c.columns = append([]initializer(nil), b.columns...)
+	return &c
+}
+
 // Into sets the INTO clause of the query.
 // See the package documentation for table name quoting rules.
 func (b *InsertBuilder) Into(table string) *InsertBuilder {
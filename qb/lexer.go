@@ -0,0 +1,160 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind specifies type of a CQL token.
+type tokenKind byte
+
+const (
+	tokIdent  tokenKind = iota // Unquoted identifier or keyword.
+	tokQuoted                  // Quoted identifier.
+	tokString                  // String literal, '...' or $$...$$.
+	tokNumber                  // Number, UUID prefix or blob.
+	tokMarker                  // Bind marker ?.
+	tokNamed                   // Named bind marker :name.
+	tokPunct                   // Punctuation or operator.
+)
+
+type token struct {
+	text string
+	pos  int
+	end  int
+	kind tokenKind
+}
+
+// is reports whether t is the given punctuation or a keyword, keywords are
+// case insensitive.
+func (t token) is(s string) bool {
+	switch t.kind {
+	case tokPunct:
+		return t.text == s
+	case tokIdent:
+		return strings.EqualFold(t.text, s)
+	default:
+		return false
+	}
+}
+
+// lex splits CQL statement into tokens, whitespace and comments are skipped.
+func lex(stmt string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(stmt); {
+		b := stmt[i]
+		start := i
+		kind := tokPunct
+
+		switch {
+		case b == ' ' || b == '\t' || b == '\n' || b == '\r':
+			i++
+			continue
+		case b == '-' && strings.HasPrefix(stmt[i:], "--"), b == '/' && strings.HasPrefix(stmt[i:], "//"):
+			if n := strings.IndexByte(stmt[i:], '\n'); n >= 0 {
+				i += n + 1
+			} else {
+				i = len(stmt)
+			}
+			continue
+		case b == '/' && strings.HasPrefix(stmt[i:], "/*"):
+			n := strings.Index(stmt[i+2:], "*/")
+			if n < 0 {
+				return nil, fmt.Errorf("unterminated comment at %d", i)
+			}
+			i += n + 4
+			continue
+		case b == '\'' || b == '"':
+			kind = tokString
+			if b == '"' {
+				kind = tokQuoted
+			}
+			i++
+			for {
+				n := strings.IndexByte(stmt[i:], b)
+				if n < 0 {
+					return nil, fmt.Errorf("unterminated quote at %d", start)
+				}
+				i += n + 1
+				if i < len(stmt) && stmt[i] == b {
+					i++
+					continue
+				}
+				break
+			}
+		case b == '$' && strings.HasPrefix(stmt[i:], "$$"):
+			kind = tokString
+			n := strings.Index(stmt[i+2:], "$$")
+			if n < 0 {
+				return nil, fmt.Errorf("unterminated quote at %d", start)
+			}
+			i += n + 4
+		case isIdentStart(b):
+			kind = tokIdent
+			for i < len(stmt) && isIdentPart(stmt[i]) {
+				i++
+			}
+		case b >= '0' && b <= '9':
+			kind = tokNumber
+			for i < len(stmt) && (isIdentPart(stmt[i]) || stmt[i] == '.' ||
+				(stmt[i] == '+' || stmt[i] == '-') && (stmt[i-1] == 'e' || stmt[i-1] == 'E') && isDecimal(stmt[start:i-1])) {
+				i++
+			}
+		case b == '?':
+			kind = tokMarker
+			i++
+		case b == ':' && i+1 < len(stmt) && (isIdentStart(stmt[i+1]) || stmt[i+1] == '"'):
+			kind = tokNamed
+			i++
+			if stmt[i] == '"' {
+				n := strings.IndexByte(stmt[i+1:], '"')
+				if n < 0 {
+					return nil, fmt.Errorf("unterminated quote at %d", i)
+				}
+				i += n + 2
+			} else {
+				for i < len(stmt) && isIdentPart(stmt[i]) {
+					i++
+				}
+			}
+		case b == '<' || b == '>' || b == '!':
+			i++
+			if i < len(stmt) && stmt[i] == '=' {
+				i++
+			}
+		case strings.IndexByte("()[]{},;.=:+-*/%", b) >= 0:
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", b, i)
+		}
+
+		tokens = append(tokens, token{
+			text: stmt[start:i],
+			pos:  start,
+			end:  i,
+			kind: kind,
+		})
+	}
+	return tokens, nil
+}
+
+func isIdentStart(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b == '_'
+}
+
+func isIdentPart(b byte) bool {
+	return isIdentStart(b) || b >= '0' && b <= '9'
+}
+
+func isDecimal(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && s[i] != '.' {
+			return false
+		}
+	}
+	return true
+}
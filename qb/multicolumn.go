@@ -0,0 +1,165 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MultiColumnBuilder builds multi-column relations on clustering columns,
+// i.e. (a,b)>(?,?). They are used for pagination over composite clustering
+// keys. The Named methods panic if the number of names does not match the
+// number of parameters.
+type MultiColumnBuilder []string
+
+// MultiColumn creates a new MultiColumnBuilder.
+func MultiColumn(columns ...string) MultiColumnBuilder {
+	return columns
+}
+
+// Eq produces (column,...)=(?,...).
+func (m MultiColumnBuilder) Eq() Cmp {
+	return m.cmp(eq, m)
+}
+
+// EqNamed produces (column,...)=(?,...) with custom parameter names.
+func (m MultiColumnBuilder) EqNamed(names ...string) Cmp {
+	return m.cmp(eq, m.named(len(m), names))
+}
+
+// Lt produces (column,...)<(?,...).
+func (m MultiColumnBuilder) Lt() Cmp {
+	return m.cmp(lt, m)
+}
+
+// LtNamed produces (column,...)<(?,...) with custom parameter names.
+func (m MultiColumnBuilder) LtNamed(names ...string) Cmp {
+	return m.cmp(lt, m.named(len(m), names))
+}
+
+// LtOrEq produces (column,...)<=(?,...).
+func (m MultiColumnBuilder) LtOrEq() Cmp {
+	return m.cmp(leq, m)
+}
+
+// LtOrEqNamed produces (column,...)<=(?,...) with custom parameter names.
+func (m MultiColumnBuilder) LtOrEqNamed(names ...string) Cmp {
+	return m.cmp(leq, m.named(len(m), names))
+}
+
+// Gt produces (column,...)>(?,...).
+func (m MultiColumnBuilder) Gt() Cmp {
+	return m.cmp(gt, m)
+}
+
+// GtNamed produces (column,...)>(?,...) with custom parameter names.
+func (m MultiColumnBuilder) GtNamed(names ...string) Cmp {
+	return m.cmp(gt, m.named(len(m), names))
+}
+
+// GtOrEq produces (column,...)>=(?,...).
+func (m MultiColumnBuilder) GtOrEq() Cmp {
+	return m.cmp(geq, m)
+}
+
+// GtOrEqNamed produces (column,...)>=(?,...) with custom parameter names.
+func (m MultiColumnBuilder) GtOrEqNamed(names ...string) Cmp {
+	return m.cmp(geq, m.named(len(m), names))
+}
+
+// In produces (column,...) IN ((?,...),...) with count tuples. Parameters
+// are named column[i] where i is the tuple index, i.e. a[0], b[0], a[1]...
+func (m MultiColumnBuilder) In(count int) Cmp {
+	return m.in(indexedNames(m, count))
+}
+
+// InNamed produces (column,...) IN ((?,...),...) with count tuples and
+// custom parameter names, names are listed tuple by tuple and there must be
+// count names for every column.
+func (m MultiColumnBuilder) InNamed(count int, names ...string) Cmp {
+	return m.in(m.named(count*len(m), names))
+}
+
+// InValue produces (column,...) IN ? for binding a list of tuples.
+func (m MultiColumnBuilder) InValue(name string) Cmp {
+	return Cmp{
+		op:     in,
+		column: m.column(),
+		value:  param(name),
+	}
+}
+
+func (m MultiColumnBuilder) cmp(op op, names []string) Cmp {
+	return Cmp{
+		op:     op,
+		column: m.column(),
+		value:  paramList(names),
+	}
+}
+
+func (m MultiColumnBuilder) in(names []string) Cmp {
+	return Cmp{
+		op:     in,
+		column: m.column(),
+		value:  multiColumnIn{names: names, size: len(m)},
+	}
+}
+
+// named panics if the number of names is not n.
+func (m MultiColumnBuilder) named(n int, names []string) []string {
+	if len(names) != n {
+		panic(fmt.Sprintf("qb: multi-column relation on %s requires %d names, got %d", m.column(), n, len(names)))
+	}
+	return names
+}
+
+func (m MultiColumnBuilder) column() string {
+	return "(" + strings.Join(m, ",") + ")"
+}
+
+// paramList is a parenthesized list of named parameters.
+type paramList []string
+
+func (p paramList) writeCql(cql *bytes.Buffer) (names []string) {
+	cql.WriteByte('(')
+	placeholders(cql, len(p))
+	cql.WriteByte(')')
+	return append(names, p...)
+}
+
+// multiColumnIn is a parenthesized list of parameter lists of size names.
+type multiColumnIn struct {
+	names []string
+	size  int
+}
+
+func (m multiColumnIn) writeCql(cql *bytes.Buffer) (names []string) {
+	cql.WriteByte('(')
+	for i := 0; i < len(m.names); i += m.size {
+		if i > 0 {
+			cql.WriteByte(',')
+		}
+		cql.WriteByte('(')
+		placeholders(cql, m.size)
+		cql.WriteByte(')')
+	}
+	cql.WriteByte(')')
+	return append(names, m.names...)
+}
+
+// indexedNames returns names column[i] for count tuples of columns.
+func indexedNames(columns []string, count int) []string {
+	names := make([]string, 0, count*len(columns))
+	for i := 0; i < count; i++ {
+		idx := "[" + strconv.Itoa(i) + "]"
+		for _, c := range columns {
+			names = append(names, c+idx)
+		}
+	}
+	return names
+}
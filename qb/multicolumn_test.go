@@ -0,0 +1,124 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMultiColumn(t *testing.T) {
+	table := []struct {
+		C Cmp
+		S string
+		N []string
+	}{
+		// Basic comparators
+		{
+			C: MultiColumn("a", "b").Eq(),
+			S: "(a,b)=(?,?)",
+			N: []string{"a", "b"},
+		},
+		{
+			C: MultiColumn("a", "b").Lt(),
+			S: "(a,b)<(?,?)",
+			N: []string{"a", "b"},
+		},
+		{
+			C: MultiColumn("a", "b").LtOrEq(),
+			S: "(a,b)<=(?,?)",
+			N: []string{"a", "b"},
+		},
+		{
+			C: MultiColumn("a", "b").Gt(),
+			S: "(a,b)>(?,?)",
+			N: []string{"a", "b"},
+		},
+		{
+			C: MultiColumn("a", "b").GtOrEq(),
+			S: "(a,b)>=(?,?)",
+			N: []string{"a", "b"},
+		},
+		{
+			C: MultiColumn("a", "b").In(2),
+			S: "(a,b) IN ((?,?),(?,?))",
+			N: []string{"a[0]", "b[0]", "a[1]", "b[1]"},
+		},
+		{
+			C: MultiColumn("a", "b").InValue("ab"),
+			S: "(a,b) IN ?",
+			N: []string{"ab"},
+		},
+
+		// Custom bind names
+		{
+			C: MultiColumn("a", "b").EqNamed("c", "d"),
+			S: "(a,b)=(?,?)",
+			N: []string{"c", "d"},
+		},
+		{
+			C: MultiColumn("a", "b").LtNamed("c", "d"),
+			S: "(a,b)<(?,?)",
+			N: []string{"c", "d"},
+		},
+		{
+			C: MultiColumn("a", "b").LtOrEqNamed("c", "d"),
+			S: "(a,b)<=(?,?)",
+			N: []string{"c", "d"},
+		},
+		{
+			C: MultiColumn("a", "b").GtNamed("c", "d"),
+			S: "(a,b)>(?,?)",
+			N: []string{"c", "d"},
+		},
+		{
+			C: MultiColumn("a", "b").GtOrEqNamed("c", "d"),
+			S: "(a,b)>=(?,?)",
+			N: []string{"c", "d"},
+		},
+		{
+			C: MultiColumn("a", "b").InNamed(2, "c", "d", "e", "f"),
+			S: "(a,b) IN ((?,?),(?,?))",
+			N: []string{"c", "d", "e", "f"},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	for _, test := range table {
+		buf.Reset()
+		name := test.C.writeCql(buf)
+		if diff := cmp.Diff(test.S, buf.String()); diff != "" {
+			t.Error(diff)
+		}
+		if diff := cmp.Diff(test.N, name); diff != "" {
+			t.Error(diff)
+		}
+	}
+}
+
+func TestMultiColumnNamedPanics(t *testing.T) {
+	m := MultiColumn("a", "b")
+	table := map[string]func(){
+		"EqNamed":     func() { m.EqNamed("c") },
+		"LtNamed":     func() { m.LtNamed("c", "d", "e") },
+		"LtOrEqNamed": func() { m.LtOrEqNamed() },
+		"GtNamed":     func() { m.GtNamed("c") },
+		"GtOrEqNamed": func() { m.GtOrEqNamed("c") },
+		"InNamed":     func() { m.InNamed(2, "c", "d") },
+	}
+
+	for name, f := range table {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatalf("%s() should panic on names mismatch", name)
+				}
+			}()
+			f()
+		})
+	}
+}
@@ -0,0 +1,976 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse parses a CQL SELECT, INSERT, UPDATE or DELETE statement into
+// the corresponding builder, so that it can be modified, i.e. to add a WHERE
+// condition, LIMIT or USING TIMEOUT. The returned builder is
+// a *SelectBuilder, *InsertBuilder, *UpdateBuilder or *DeleteBuilder.
+//
+// Named bind markers :name are converted to ? with the given names. Unnamed
+// markers are named after the builder conventions, i.e. id in id=?, m_key
+// in m[?]=? or limit in LIMIT ?. Literals, function calls and other terms
+// that can't be represented with builder methods are kept verbatim.
+func Parse(stmt string) (Builder, error) {
+	tokens, err := lex(stmt)
+	if err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	p := parser{stmt: stmt, tokens: tokens}
+
+	var b Builder
+	switch {
+	case p.peek().is("SELECT"):
+		b, err = p.parseSelect()
+	case p.peek().is("INSERT"):
+		b, err = p.parseInsert()
+	case p.peek().is("UPDATE"):
+		b, err = p.parseUpdate()
+	case p.peek().is("DELETE"):
+		b, err = p.parseDelete()
+	default:
+		err = p.errorf("expected SELECT, INSERT, UPDATE or DELETE")
+	}
+	if err == nil {
+		p.accept(";")
+		if !p.eof() {
+			err = p.errorf("unexpected input")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	return b, nil
+}
+
+// clauseKeywords end terms and relations.
+var clauseKeywords = []string{
+	"FROM", "WHERE", "GROUP", "ORDER", "PER", "LIMIT", "ALLOW", "BYPASS",
+	"USING", "SET", "IF", "VALUES", "AND",
+}
+
+type parser struct {
+	stmt   string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.eof() {
+		return token{kind: tokPunct, pos: len(p.stmt), end: len(p.stmt)}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if !p.eof() {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next tokens if they match s.
+func (p *parser) accept(s ...string) bool {
+	if p.pos+len(s) > len(p.tokens) {
+		return false
+	}
+	for i, v := range s {
+		if !p.tokens[p.pos+i].is(v) {
+			return false
+		}
+	}
+	p.pos += len(s)
+	return true
+}
+
+func (p *parser) expect(s ...string) error {
+	if !p.accept(s...) {
+		return p.errorf("expected %s", strings.Join(s, " "))
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	if p.eof() {
+		return fmt.Errorf(format+" at end of statement", args...)
+	}
+	return fmt.Errorf(format+" at %d near %q", append(args, t.pos, t.text)...)
+}
+
+// identifier consumes an identifier and returns its text.
+func (p *parser) identifier() (string, error) {
+	t := p.peek()
+	if t.kind != tokIdent && t.kind != tokQuoted {
+		return "", p.errorf("expected identifier")
+	}
+	p.pos++
+	return t.text, nil
+}
+
+// tableName consumes an optionally keyspace qualified table name.
+func (p *parser) tableName() (string, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return "", err
+	}
+	if p.accept(".") {
+		table, err := p.identifier()
+		if err != nil {
+			return "", err
+		}
+		name += "." + table
+	}
+	return name, nil
+}
+
+// identifierList consumes comma separated identifiers.
+func (p *parser) identifierList() ([]string, error) {
+	var v []string
+	for {
+		id, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		v = append(v, id)
+		if !p.accept(",") {
+			return v, nil
+		}
+	}
+}
+
+// term consumes tokens up to a top level comma, closing bracket, clause
+// keyword or one of the stop tokens and returns the index range.
+func (p *parser) term(stop ...string) (from, to int, err error) {
+	from = p.pos
+	depth := 0
+loop:
+	for ; !p.eof(); p.pos++ {
+		t := p.tokens[p.pos]
+		if t.kind == tokPunct {
+			switch t.text {
+			case "(", "[", "{":
+				depth++
+				continue
+			case ")", "]", "}":
+				if depth == 0 {
+					break loop
+				}
+				depth--
+				continue
+			case ",", ";":
+				if depth == 0 {
+					break loop
+				}
+			}
+		}
+		if depth == 0 && (isKeyword(t, clauseKeywords) || isKeyword(t, stop)) {
+			break
+		}
+	}
+	if p.pos == from {
+		return 0, 0, p.errorf("expected term")
+	}
+	return from, p.pos, nil
+}
+
+func isKeyword(t token, keywords []string) bool {
+	for _, k := range keywords {
+		if t.is(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// text returns the statement text of tokens in [from, to).
+func (p *parser) text(from, to int) string {
+	return p.stmt[p.tokens[from].pos:p.tokens[to-1].end]
+}
+
+// value returns value of tokens in [from, to), unnamed markers are named
+// name if there is one or name[i] otherwise.
+func (p *parser) value(from, to int, name string) value {
+	toks := p.tokens[from:to]
+
+	if len(toks) == 1 && (toks[0].kind == tokMarker || toks[0].kind == tokNamed) {
+		return param(markerName(toks[0], name))
+	}
+
+	// (?,?,...)
+	if n, ok := markerList(toks, 1); ok && n > 0 {
+		if unnamedMarkers(toks) {
+			return tupleParam{param: param(name), count: n}
+		}
+		return paramList(markerNames(toks, name, n))
+	}
+
+	// fn(?,...)
+	if len(toks) >= 3 && toks[0].kind == tokIdent && toks[1].is("(") {
+		if n, ok := markerList(toks, 2); ok {
+			return Fn(toks[0].text, markerNames(toks, name, n)...)
+		}
+	}
+
+	return p.verbatim(from, to, name)
+}
+
+// verbatim returns tokens in [from, to) as written, unnamed markers are
+// named name if there is one or name[i] otherwise.
+func (p *parser) verbatim(from, to int, name string) value {
+	toks := p.tokens[from:to]
+
+	n := 0
+	for _, t := range toks {
+		if t.kind == tokMarker || t.kind == tokNamed {
+			n++
+		}
+	}
+	if n == 0 {
+		return lit(p.text(from, to))
+	}
+
+	var (
+		cql   strings.Builder
+		names []string
+		last  = toks[0].pos
+		i     = 0
+	)
+	for _, t := range toks {
+		if t.kind != tokMarker && t.kind != tokNamed {
+			continue
+		}
+		cql.WriteString(p.stmt[last:t.pos])
+		cql.WriteByte('?')
+		last = t.end
+		if n == 1 {
+			names = append(names, markerName(t, name))
+		} else {
+			names = append(names, markerName(t, name+"["+strconv.Itoa(i)+"]"))
+		}
+		i++
+	}
+	cql.WriteString(p.stmt[last:toks[len(toks)-1].end])
+
+	return verbatim{cql: cql.String(), names: names}
+}
+
+// markerList returns number of markers if tokens from the start index are
+// a parenthesised list of markers (?,:name,...).
+func markerList(toks []token, start int) (int, bool) {
+	if len(toks) < start+1 || !toks[start-1].is("(") || !toks[len(toks)-1].is(")") {
+		return 0, false
+	}
+	inner := toks[start : len(toks)-1]
+	if len(inner) == 0 {
+		return 0, true
+	}
+	for i, t := range inner {
+		if i%2 == 0 && t.kind != tokMarker && t.kind != tokNamed {
+			return 0, false
+		}
+		if i%2 == 1 && !t.is(",") {
+			return 0, false
+		}
+	}
+	if len(inner)%2 == 0 {
+		return 0, false
+	}
+	return (len(inner) + 1) / 2, true
+}
+
+func unnamedMarkers(toks []token) bool {
+	for _, t := range toks {
+		if t.kind == tokNamed {
+			return false
+		}
+	}
+	return true
+}
+
+// markerNames returns names of markers in toks, unnamed markers are named
+// name if n is 1 or name[i] otherwise.
+func markerNames(toks []token, name string, n int) []string {
+	names := make([]string, 0, n)
+	for _, t := range toks {
+		if t.kind != tokMarker && t.kind != tokNamed {
+			continue
+		}
+		if n == 1 {
+			names = append(names, markerName(t, name))
+		} else {
+			names = append(names, markerName(t, name+"["+strconv.Itoa(len(names))+"]"))
+		}
+	}
+	return names
+}
+
+func markerName(t token, name string) string {
+	if t.kind == tokNamed {
+		return unquote(t.text[1:])
+	}
+	return name
+}
+
+// unquote returns the name of a quoted identifier.
+func unquote(identifier string) string {
+	if len(identifier) >= 2 && identifier[0] == '"' && identifier[len(identifier)-1] == '"' {
+		return strings.ReplaceAll(identifier[1:len(identifier)-1], `""`, `"`)
+	}
+	return identifier
+}
+
+// verbatim is CQL text with named parameters.
+type verbatim struct {
+	cql   string
+	names []string
+}
+
+func (v verbatim) writeCql(cql *bytes.Buffer) (names []string) {
+	cql.WriteString(v.cql)
+	return v.names
+}
+
+// keyed is a relation value preceded by markers in the column, i.e. m[?]=?.
+type keyed struct {
+	names []string
+	value value
+}
+
+func (k keyed) writeCql(cql *bytes.Buffer) (names []string) {
+	names = append(names, k.names...)
+	return append(names, k.value.writeCql(cql)...)
+}
+
+func (p *parser) parseSelect() (*SelectBuilder, error) {
+	b := &SelectBuilder{}
+	p.next()
+
+	if p.accept("JSON") {
+		b.json = true
+	}
+	distinct := p.accept("DISTINCT")
+
+	if p.accept("*") {
+		if distinct {
+			return nil, p.errorf("expected columns")
+		}
+	} else {
+		for {
+			from, to, err := p.term("AS")
+			if err != nil {
+				return nil, err
+			}
+			if p.accept("AS") {
+				if _, err := p.identifier(); err != nil {
+					return nil, err
+				}
+				to = p.pos
+			}
+			if distinct {
+				if to-from != 1 {
+					return nil, p.errorf("expected column")
+				}
+				b.distinct = append(b.distinct, p.text(from, to))
+			} else {
+				b.columns = append(b.columns, p.selector(from, to))
+			}
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	table, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+	b.table = table
+
+	if p.accept("WHERE") {
+		if b.where, err = p.relations(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("GROUP", "BY") {
+		if b.groupBy, err = p.identifierList(); err != nil {
+			return nil, err
+		}
+		// Group by columns are written as the first selectors
+		b.columns = trimGroupBy(b.columns, b.groupBy)
+	}
+	if p.accept("ORDER", "BY") {
+		for {
+			if err := p.ordering(b); err != nil {
+				return nil, err
+			}
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if p.accept("PER", "PARTITION", "LIMIT") {
+		if b.limitPerPartition, err = p.limit("partition_limit", true); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("LIMIT") {
+		if b.limit, err = p.limit("limit", false); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("ALLOW", "FILTERING") {
+		b.allowFiltering = true
+	}
+	if p.accept("BYPASS", "CACHE") {
+		b.bypassCache = true
+	}
+	if p.accept("USING") {
+		if err := p.using(&b.using); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// trimGroupBy removes leading selectors that are the group by columns.
+func trimGroupBy(selectors valueList, groupBy []string) valueList {
+	if len(selectors) < len(groupBy) {
+		return selectors
+	}
+	for i, c := range groupBy {
+		if v, ok := selectors[i].(lit); !ok || string(v) != c {
+			return selectors
+		}
+	}
+	return selectors[len(groupBy):]
+}
+
+// selector returns selector of tokens in [from, to).
+func (p *parser) selector(from, to int) value {
+	if to-from == 1 {
+		return lit(p.text(from, to))
+	}
+
+	// Markers are named after the first column, i.e. v in fn(v, ?)
+	toks := p.tokens[from:to]
+	name := ""
+	for i, t := range toks {
+		if (t.kind == tokIdent || t.kind == tokQuoted) && (i+1 == len(toks) || !toks[i+1].is("(")) {
+			name = unquote(t.text)
+			break
+		}
+	}
+	// column[?]
+	if len(toks) >= 4 && toks[1].is("[") && toks[2].kind == tokMarker && toks[3].is("]") {
+		name = elemKey(name)
+	}
+	return p.verbatim(from, to, name)
+}
+
+func (p *parser) ordering(b *SelectBuilder) error {
+	column, err := p.identifier()
+	if err != nil {
+		return err
+	}
+	switch {
+	case p.accept("ANN", "OF"):
+		from, to, err := p.term()
+		if err != nil {
+			return err
+		}
+		b.orderBy = append(b.orderBy, concat{lit(column + " ANN OF "), p.value(from, to, unquote(column))})
+	case p.accept("DESC"):
+		b.OrderBy(column, DESC)
+	default:
+		p.accept("ASC")
+		b.OrderBy(column, ASC)
+	}
+	return nil
+}
+
+func (p *parser) limit(name string, perPartition bool) (limit, error) {
+	switch p.peek().kind {
+	case tokNumber, tokMarker, tokNamed:
+	default:
+		return limit{}, p.errorf("expected limit")
+	}
+	p.next()
+	return limit{value: p.value(p.pos-1, p.pos, name), perPartition: perPartition}, nil
+}
+
+// using parses USING clause options.
+func (p *parser) using(u *using) error {
+	for {
+		var (
+			option = p.next()
+			name   string
+		)
+		switch {
+		case option.is("TTL"):
+			name = "ttl"
+		case option.is("TIMESTAMP"):
+			name = "timestamp"
+		case option.is("TIMEOUT"):
+			name = "timeout"
+		default:
+			p.pos--
+			return p.errorf("expected TTL, TIMESTAMP or TIMEOUT")
+		}
+
+		t := p.next()
+		switch {
+		case t.kind == tokMarker || t.kind == tokNamed:
+			name = markerName(t, name)
+			switch {
+			case option.is("TTL"):
+				u.TTLNamed(name)
+			case option.is("TIMESTAMP"):
+				u.TimestampNamed(name)
+			default:
+				u.TimeoutNamed(name)
+			}
+		case option.is("TIMEOUT"):
+			d, err := time.ParseDuration(t.text)
+			if err != nil {
+				p.pos--
+				return p.errorf("invalid duration")
+			}
+			u.Timeout(d)
+		default:
+			v, err := strconv.ParseInt(t.text, 10, 64)
+			if err != nil {
+				p.pos--
+				return p.errorf("expected integer")
+			}
+			if option.is("TTL") {
+				u.ttl, u.ttlName = v, ""
+				if v == 0 {
+					u.ttl = -1
+				}
+			} else {
+				u.timestamp, u.timestampName = v, ""
+			}
+		}
+
+		if !p.accept("AND") {
+			return nil
+		}
+	}
+}
+
+// relations parses relations separated by AND.
+func (p *parser) relations() ([]Cmp, error) {
+	var cmps []Cmp
+	for {
+		c, err := p.relation()
+		if err != nil {
+			return nil, err
+		}
+		cmps = append(cmps, c)
+		if !p.accept("AND") {
+			return cmps, nil
+		}
+	}
+}
+
+func (p *parser) relation() (Cmp, error) {
+	var (
+		c       Cmp
+		columns []string
+		name    string
+		key     []string
+		token   bool
+	)
+
+	switch {
+	case p.accept("("):
+		ids, err := p.identifierList()
+		if err != nil {
+			return c, err
+		}
+		if err := p.expect(")"); err != nil {
+			return c, err
+		}
+		columns = ids
+		c.column = "(" + strings.Join(ids, ",") + ")"
+		name = strings.Join(ids, ",")
+	case p.peek().is("TOKEN") && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].is("("):
+		p.pos += 2
+		ids, err := p.identifierList()
+		if err != nil {
+			return c, err
+		}
+		if err := p.expect(")"); err != nil {
+			return c, err
+		}
+		columns, token = ids, true
+		c.column = "token(" + strings.Join(ids, ",") + ")"
+		name = "token"
+	default:
+		from := p.pos
+		id, err := p.identifier()
+		if err != nil {
+			return c, err
+		}
+		name = unquote(id)
+		if p.accept("[") {
+			from, to, err := p.term()
+			if err != nil {
+				return c, err
+			}
+			if err := p.expect("]"); err != nil {
+				return c, err
+			}
+			var cql bytes.Buffer
+			key = elem{column: id, key: p.value(from, to, elemKey(name))}.writeCql(&cql)
+			c.column = cql.String()
+		} else {
+			if p.accept(".") {
+				field, err := p.identifier()
+				if err != nil {
+					return c, err
+				}
+				name += "." + unquote(field)
+			}
+			c.column = p.text(from, p.pos)
+		}
+	}
+
+	switch {
+	case p.accept("="):
+		c.op = eq
+	case p.accept("!="):
+		c.op = ne
+	case p.accept("<"):
+		c.op = lt
+	case p.accept("<="):
+		c.op = leq
+	case p.accept(">"):
+		c.op = gt
+	case p.accept(">="):
+		c.op = geq
+	case p.accept("IN"):
+		c.op = in
+	case p.accept("CONTAINS", "KEY"):
+		c.op = cntKey
+	case p.accept("CONTAINS"):
+		c.op = cnt
+	case p.accept("LIKE"):
+		c.op = like
+	default:
+		return c, p.errorf("expected operator")
+	}
+
+	from, to, err := p.term()
+	if err != nil {
+		return c, err
+	}
+	toks := p.tokens[from:to]
+
+	switch {
+	case token && len(toks) > 1:
+		// token(?,?)
+		if n, ok := markerList(toks, 2); ok && toks[0].is("TOKEN") && n == len(columns) && unnamedMarkers(toks) {
+			c.value = Fn("token", columns...)
+			return c, nil
+		}
+	case columns != nil && !token:
+		// (?,?)
+		if n, ok := markerList(toks, 1); ok && n == len(columns) && unnamedMarkers(toks) {
+			c.value = paramList(columns)
+			return c, nil
+		}
+		// ((?,?),(?,?))
+		if n, ok := p.multiColumnIn(toks, len(columns)); ok && c.op == in {
+			c.value = multiColumnIn{names: indexedNames(columns, n), size: len(columns)}
+			return c, nil
+		}
+	}
+	c.value = p.value(from, to, name)
+	if len(key) > 0 {
+		c.value = keyed{names: key, value: c.value}
+	}
+
+	return c, nil
+}
+
+// multiColumnIn returns number of tuples if toks are a list of tuples of
+// n unnamed markers.
+func (p *parser) multiColumnIn(toks []token, n int) (int, bool) {
+	if len(toks) < 2 || !toks[0].is("(") || !toks[len(toks)-1].is(")") {
+		return 0, false
+	}
+	inner := toks[1 : len(toks)-1]
+	count := 0
+	for len(inner) > 0 {
+		size := 2*n + 1
+		if len(inner) < size {
+			return 0, false
+		}
+		if m, ok := markerList(inner[:size], 1); !ok || m != n || !unnamedMarkers(inner[:size]) {
+			return 0, false
+		}
+		count++
+		inner = inner[size:]
+		if len(inner) > 0 {
+			if !inner[0].is(",") {
+				return 0, false
+			}
+			inner = inner[1:]
+		}
+	}
+	return count, count > 0
+}
+
+// conditions parses IF clause of UPDATE and DELETE statements.
+func (p *parser) conditions() (exists bool, cmps []Cmp, err error) {
+	if p.accept("EXISTS") {
+		return true, nil, nil
+	}
+	cmps, err = p.relations()
+	return false, cmps, err
+}
+
+func (p *parser) parseInsert() (*InsertBuilder, error) {
+	b := &InsertBuilder{}
+	p.next()
+
+	if err := p.expect("INTO"); err != nil {
+		return nil, err
+	}
+	table, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+	b.table = table
+
+	if p.accept("JSON") {
+		b.json = true
+		if t := p.next(); t.kind != tokMarker && t.kind != tokNamed {
+			p.pos--
+			return nil, p.errorf("expected bind marker")
+		}
+	} else {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		columns, err := p.identifierList()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")", "VALUES", "("); err != nil {
+			return nil, err
+		}
+		for i, column := range columns {
+			if i > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			from, to, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			b.columns = append(b.columns, initializer{
+				column: column,
+				value:  p.value(from, to, unquote(column)),
+			})
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.accept("IF", "NOT", "EXISTS") {
+		b.unique = true
+	}
+	if p.accept("USING") {
+		if err := p.using(&b.using); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+func (p *parser) parseUpdate() (*UpdateBuilder, error) {
+	b := &UpdateBuilder{}
+	p.next()
+
+	table, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+	b.table = table
+
+	if p.accept("USING") {
+		if err := p.using(&b.using); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("SET"); err != nil {
+		return nil, err
+	}
+	for {
+		a, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+		b.assignments = append(b.assignments, a)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect("WHERE"); err != nil {
+		return nil, err
+	}
+	if b.where, err = p.relations(); err != nil {
+		return nil, err
+	}
+	if p.accept("IF") {
+		if b.exists, b._if, err = p.conditions(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("ALLOW", "FILTERING") {
+		b.allowFiltering = true
+	}
+
+	return b, nil
+}
+
+func (p *parser) assignment() (assignment, error) {
+	var a assignment
+
+	column, err := p.identifier()
+	if err != nil {
+		return a, err
+	}
+	a.column = column
+	name := unquote(column)
+
+	switch {
+	case p.accept("["):
+		from, to, err := p.term()
+		if err != nil {
+			return a, err
+		}
+		a.target = elem{column: column, key: p.value(from, to, elemKey(name))}
+		if err := p.expect("]"); err != nil {
+			return a, err
+		}
+	case p.accept("."):
+		field, err := p.identifier()
+		if err != nil {
+			return a, err
+		}
+		a.column += "." + field
+		name += "." + unquote(field)
+	}
+
+	if err := p.expect("="); err != nil {
+		return a, err
+	}
+	from, to, err := p.term("WHERE")
+	if err != nil {
+		return a, err
+	}
+	toks := p.tokens[from:to]
+
+	switch {
+	// column=column+? or column=column-?
+	case a.target == nil && len(toks) > 2 && toks[0].text == column && (toks[1].is("+") || toks[1].is("-")):
+		a.valuePrefix = column + toks[1].text
+		a.value = p.value(from+2, to, name)
+	// column=?+column
+	case a.target == nil && len(toks) > 2 && toks[len(toks)-1].text == column && toks[len(toks)-2].is("+"):
+		a.valueSuffix = "+" + column
+		a.value = p.value(from, to-2, name)
+	default:
+		a.value = p.value(from, to, name)
+	}
+
+	return a, nil
+}
+
+func (p *parser) parseDelete() (*DeleteBuilder, error) {
+	b := &DeleteBuilder{}
+	p.next()
+
+	for !p.peek().is("FROM") {
+		column, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case p.accept("["):
+			from, to, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			b.columns = append(b.columns, elem{column: column, key: p.value(from, to, elemKey(unquote(column)))})
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+		case p.accept("."):
+			field, err := p.identifier()
+			if err != nil {
+				return nil, err
+			}
+			b.ColumnField(column, field)
+		default:
+			b.Columns(column)
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	table, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+	b.table = table
+
+	if p.accept("USING") {
+		if err := p.using(&b.using); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("WHERE"); err != nil {
+		return nil, err
+	}
+	if b.where, err = p.relations(); err != nil {
+		return nil, err
+	}
+	if p.accept("IF") {
+		if b.exists, b._if, err = p.conditions(); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
@@ -0,0 +1,189 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	// Names of unnamed markers follow the builder conventions
+	w := Eq("id")
+
+	table := []Builder{
+		Select("cycling.cyclist_name"),
+		Select("cycling.cyclist_name").Columns("id", "user_uuid", As("firstname", "name")).Json(),
+		Select("cycling.cyclist_name").Distinct("id"),
+		Select(`ks."Foobar"`).Where(w, Gt("firstname"), InTuple("stars", 3)),
+		Select("cycling.cyclist_name").Where(EqTuple("id", 2), GtTuple("firstname", 2), LtOrEqLit("stars", "5")),
+		Select("cycling.cyclist_name").Where(Contains("tags"), ContainsKey("attrs"), Like("name"), Ne("deleted"), In("team")),
+		Select("cycling.cyclist_name").Where(EqLitString("name", "O'Brien"), EqFunc("created", MaxTimeuuid("created"))),
+		Select("cycling.cyclist_name").Where(Token("id", "name").Gt(), Token("id").LtValue()),
+		Select("cycling.cyclist_name").Where(MultiColumn("a", "b").Gt(), MultiColumn("a", "b").In(2)),
+		Select("cycling.cyclist_name").Columns("id", "stars").GroupBy("id").Max("stars"),
+		Select("cycling.cyclist_name").OrderBy("firstname", ASC).OrderBy("lastname", DESC),
+		Select("cycling.cyclist_name").Where(w).LimitPerPartitionNamed("partition_limit").LimitNamed("limit"),
+		Select("cycling.cyclist_name").Limit(10).LimitPerPartition(2).AllowFiltering().BypassCache().Timeout(time.Second),
+		Select("cycling.cyclist_name").Selectors(WriteTime("name").As("w"), Elem("m"), Cast("id", "text"), SliceLit("s", "1", "2")),
+		Select("cycling.cyclist_name").Columns("id").OrderByANN("embedding", "embedding").Limit(5),
+		Select("cycling.cyclist_name").Selectors(SimilarityCosine("embedding", "embedding").As("score")),
+
+		Insert("cycling.cyclist_name").Columns("id", "user_uuid", "firstname"),
+		Insert("cycling.cyclist_name").Columns("id").LitColumn("name", "'Joe'").FuncColumn("created", Now()),
+		Insert("cycling.cyclist_name").Columns("id").TupleColumn("pair", 2).Unique().TTL(time.Hour).Timestamp(time.Unix(1, 0)),
+		Insert("cycling.cyclist_name").Columns("id").TTLNamed("ttl").TimestampNamed("timestamp").TimeoutNamed("timeout"),
+		Insert("cycling.cyclist_name").Json(),
+
+		Update("cycling.cyclist_name").Set("id", "user_uuid", "firstname").Where(w),
+		Update("cycling.cyclist_name").SetLit("user_uuid", "literal_uuid").SetLitString("name", "O'Brien").Where(w).If(Eq("stars")),
+		Update("cycling.cyclist_name").Add("total").Remove("tags").AddLit("count", "1").Prepend("list").Where(w).Existing(),
+		Update("cycling.cyclist_name").SetElem("m").SetField("addr", "city").SetTuple("pair", 2).SetFunc("at", Now()).Where(w),
+		Update("cycling.cyclist_name").Set("name").Where(w).TTL(0).Timeout(500 * time.Millisecond).AllowFiltering(),
+
+		Delete("cycling.cyclist_name").Where(w),
+		Delete("cycling.cyclist_name").Columns("name").ColumnElem("m").ColumnField("addr", "city").Where(w).If(Eq("stars")),
+		Delete("cycling.cyclist_name").Where(w).TimestampNamed("timestamp").Existing(),
+	}
+
+	for _, b := range table {
+		stmt, names := b.ToCql()
+		t.Run(stmt, func(t *testing.T) {
+			p, err := Parse(stmt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, n := p.ToCql()
+			if diff := cmp.Diff(stmt, s); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(names, n); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	table := []struct {
+		Input string
+		S     string
+		N     []string
+	}{
+		{
+			Input: "select * from users where id = :user_id and age > 18 limit ?;",
+			S:     "SELECT * FROM users WHERE id=? AND age>18 LIMIT ? ",
+			N:     []string{"user_id", "limit"},
+		},
+		{
+			Input: `SELECT name, "Mixed" AS m, count(*) FROM ks.users -- comment
+				WHERE tags CONTAINS 'a' AND token(id) > token(:from)`,
+			S: `SELECT name,"Mixed" AS m,count(*) FROM ks.users WHERE tags CONTAINS 'a' AND token(id)>token(?) `,
+			N: []string{"from"},
+		},
+		{
+			Input: "SELECT * FROM users WHERE id IN (1, 2) AND m[?] = ? AND (a, b) >= (?, :b)",
+			S:     "SELECT * FROM users WHERE id IN (1, 2) AND m[?]=? AND (a,b)>=(?,?) ",
+			N:     []string{"m_key", "m", "a,b[0]", "b"},
+		},
+		{
+			Input: "SELECT * FROM ks.t WHERE m[:k]=:v AND n['a'] = ?",
+			S:     "SELECT * FROM ks.t WHERE m[?]=? AND n['a']=? ",
+			N:     []string{"k", "v", "n"},
+		},
+		{
+			Input: "SELECT * FROM ks.t WHERE m[?]=? AND \"M\"[?] CONTAINS ?",
+			S:     `SELECT * FROM ks.t WHERE m[?]=? AND "M"[?] CONTAINS ? `,
+			N:     []string{"m_key", "m", "M_key", "M"},
+		},
+		{
+			Input: "INSERT INTO users (id, tags, at, pair) VALUES (uuid(), {'a', 'b'}, toTimestamp(now()), (?, ?)) USING TTL :ttl",
+			S:     "INSERT INTO users (id,tags,at,pair) VALUES (uuid(),{'a', 'b'},toTimestamp(now()),(?,?)) USING TTL ? ",
+			N:     []string{"pair[0]", "pair[1]", "ttl"},
+		},
+		{
+			Input: "UPDATE users USING TIMESTAMP 1000 SET visits = visits + 1, tags = tags + {'x'}, m['k'] = :v, \"Name\" = ? WHERE id = ? IF EXISTS",
+			S:     `UPDATE users USING TIMESTAMP 1000 SET visits=visits+1,tags=tags+{'x'},m['k']=?,"Name"=? WHERE id=? IF EXISTS `,
+			N:     []string{"v", "Name", "id"},
+		},
+		{
+			Input: "UPDATE users SET list = ['a'] + list, n = ? WHERE id = ? IF n = ? AND v != null",
+			S:     "UPDATE users SET list=['a']+list,n=? WHERE id=? IF n=? AND v!=null ",
+			N:     []string{"n", "id", "n"},
+		},
+		{
+			Input: "DELETE m[?], addr.city FROM users USING TIMESTAMP ? WHERE id = ? AND ck IN ?",
+			S:     "DELETE m[?],addr.city FROM users USING TIMESTAMP ? WHERE id=? AND ck IN ? ",
+			N:     []string{"m_key", "timestamp", "id", "ck"},
+		},
+		{
+			Input: "SELECT * FROM t WHERE v = $$it's$$ AND u = 123e4567-e89b-12d3-a456-426655440000 AND b = 0xcafe AND f = -1.5e+10",
+			S:     "SELECT * FROM t WHERE v=$$it's$$ AND u=123e4567-e89b-12d3-a456-426655440000 AND b=0xcafe AND f=-1.5e+10 ",
+		},
+	}
+
+	for _, test := range table {
+		b, err := Parse(test.Input)
+		if err != nil {
+			t.Fatal(test.Input, err)
+		}
+		stmt, names := b.ToCql()
+		if diff := cmp.Diff(test.S, stmt); diff != "" {
+			t.Error(test.Input, diff)
+		}
+		if diff := cmp.Diff(test.N, names); diff != "" {
+			t.Error(test.Input, diff)
+		}
+
+		b, err = Parse(stmt)
+		if err != nil {
+			t.Fatal(stmt, err)
+		}
+		if s, n := b.ToCql(); s != stmt || len(n) != len(names) {
+			t.Errorf("Parse(%q) got %q %q", stmt, s, n)
+		}
+	}
+}
+
+func TestParseModify(t *testing.T) {
+	b, err := Parse("SELECT id, name FROM users WHERE tenant = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, names := b.(*SelectBuilder).Where(Eq("id")).Limit(10).Timeout(time.Second).ToCql()
+	if diff := cmp.Diff("SELECT id,name FROM users WHERE tenant=? AND id=? LIMIT 10 USING TIMEOUT 1s ", stmt); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]string{"tenant", "id"}, names); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	table := []string{
+		"",
+		"TRUNCATE users",
+		"SELECT FROM users",
+		"SELECT * FROM users WHERE",
+		"SELECT * FROM users WHERE id ? 1",
+		"SELECT * FROM users LIMIT 1 foo",
+		"SELECT * FROM 'users'",
+		"INSERT INTO users (id) VALUES (?, ?)",
+		"INSERT INTO users (id) VALUES (?",
+		"UPDATE users SET name = ?",
+		"UPDATE users USING TTL abc SET name = ? WHERE id = ?",
+		"DELETE FROM users USING TIMEOUT 1x WHERE id = ?",
+		"DELETE FROM users WHERE name = 'unterminated",
+		"SELECT * FROM users /* unterminated",
+	}
+
+	for _, stmt := range table {
+		if _, err := Parse(stmt); err == nil {
+			t.Errorf("Parse(%q) expected error", stmt)
+		}
+	}
+}
@@ -39,8 +39,8 @@ type SelectBuilder struct {
 	table             string
 	where             where
 	groupBy           columns
-	orderBy           columns
-	columns           columns
+	orderBy           valueList
+	columns           valueList
 	distinct          columns
 	using             using
 	allowFiltering    bool
@@ -74,12 +74,12 @@ func (b *SelectBuilder) ToCql() (stmt string, names []string) {
 		b.groupBy.writeCql(&cql)
 		if len(b.columns) != 0 {
 			cql.WriteByte(',')
-			b.columns.writeCql(&cql)
+			names = append(names, b.columns.writeCql(&cql)...)
 		}
 	case len(b.columns) == 0:
 		cql.WriteByte('*')
 	default:
-		b.columns.writeCql(&cql)
+		names = append(names, b.columns.writeCql(&cql)...)
 	}
 	cql.WriteString(" FROM ")
 	cql.WriteString(quoteTableName(b.table))
@@ -95,7 +95,7 @@ func (b *SelectBuilder) ToCql() (stmt string, names []string) {
 
 	if len(b.orderBy) > 0 {
 		cql.WriteString("ORDER BY ")
-		b.orderBy.writeCql(&cql)
+		names = append(names, b.orderBy.writeCql(&cql)...)
 		cql.WriteByte(' ')
 	}
 
@@ -126,6 +126,18 @@ func (b *SelectBuilder) QueryContext(ctx context.Context, session gocqlx.Session
 	return b.Query(session).WithContext(ctx)
 }
 
+// Clone returns a copy of the builder, modifying the copy does not affect
+// the builder. Use it to extend a shared base builder.
+func (b *SelectBuilder) Clone() *SelectBuilder {
+	c := *b
+	c.where = append(where(nil), b.where...)
+	c.groupBy = append(columns(nil), b.groupBy...)
+	c.orderBy = append(valueList(nil), b.orderBy...)
+	c.columns = append(valueList(nil), b.columns...)
+	c.distinct = append(columns(nil), b.distinct...)
+	return &c
+}
+
 // From sets the table to be selected from.
 // See the package documentation for table name quoting rules.
 func (b *SelectBuilder) From(table string) *SelectBuilder {
@@ -141,10 +153,17 @@ func (b *SelectBuilder) Json() *SelectBuilder {
 
 // Columns adds result columns to the query.
 func (b *SelectBuilder) Columns(columns ...string) *SelectBuilder {
-	if len(b.columns) == 0 {
-		b.columns = columns
-	} else {
-		b.columns = append(b.columns, columns...)
+	for _, c := range columns {
+		b.columns = append(b.columns, lit(c))
+	}
+	return b
+}
+
+// Selectors adds result columns produced by selectors to the query, i.e.
+// Selectors(WriteTime("name").As("name_written")).
+func (b *SelectBuilder) Selectors(selectors ...Selector) *SelectBuilder {
+	for _, s := range selectors {
+		b.columns = append(b.columns, s)
 	}
 	return b
 }
@@ -180,10 +199,15 @@ func (b *SelectBuilder) TimeoutNamed(name string) *SelectBuilder {
 // Where adds an expression to the WHERE clause of the query. Expressions are
 // ANDed together in the generated CQL.
 func (b *SelectBuilder) Where(w ...Cmp) *SelectBuilder {
-	if len(b.where) == 0 {
-		b.where = w
-	} else {
-		b.where = append(b.where, w...)
+	b.where = append(b.where, w...)
+	return b
+}
+
+// WhereIf adds an expression to the WHERE clause of the query if cond is
+// true, i.e. WhereIf(req.Name != "", Eq("name")).
+func (b *SelectBuilder) WhereIf(cond bool, w ...Cmp) *SelectBuilder {
+	if cond {
+		b.Where(w...)
 	}
 	return b
 }
@@ -191,17 +215,21 @@ func (b *SelectBuilder) Where(w ...Cmp) *SelectBuilder {
 // GroupBy sets GROUP BY clause on the query. Columns must be a primary key,
 // this will automatically add the the columns as first selectors.
 func (b *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
-	if len(b.groupBy) == 0 {
-		b.groupBy = columns
-	} else {
-		b.groupBy = append(b.groupBy, columns...)
-	}
+	b.groupBy = append(b.groupBy, columns...)
 	return b
 }
 
 // OrderBy sets ORDER BY clause on the query.
 func (b *SelectBuilder) OrderBy(column string, o Order) *SelectBuilder {
-	b.orderBy = append(b.orderBy, column+" "+o.String())
+	b.orderBy = append(b.orderBy, lit(column+" "+o.String()))
+	return b
+}
+
+// OrderByANN sets ORDER BY column ANN OF ? clause on the query, it selects
+// rows with vectors nearest to the bound vector. It requires a vector index
+// on the column and a LIMIT.
+func (b *SelectBuilder) OrderByANN(column, name string) *SelectBuilder {
+	b.orderBy = append(b.orderBy, concat{lit(column + " ANN OF "), param(name)})
 	return b
 }
 
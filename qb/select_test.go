@@ -66,6 +66,12 @@ func TestSelectBuilder(t *testing.T) {
 			S: "SELECT * FROM cycling.cyclist_name WHERE id=? AND firstname>? ",
 			N: []string{"expr", "firstname"},
 		},
+		// Add WHERE conditionally
+		{
+			B: Select("cycling.cyclist_name").Where(w).WhereIf(false, Gt("firstname")).WhereIf(true, Lt("stars")),
+			S: "SELECT * FROM cycling.cyclist_name WHERE id=? AND stars<? ",
+			N: []string{"expr", "stars"},
+		},
 		// Add WHERE with tuple
 		{
 			B: Select("cycling.cyclist_name").Where(EqTuple("id", 2), Gt("firstname")),
@@ -176,6 +182,57 @@ func TestSelectBuilder(t *testing.T) {
 			S: "SELECT * FROM cycling.cyclist_name WHERE id=? BYPASS CACHE ",
 			N: []string{"expr"},
 		},
+		// Add selectors
+		{
+			B: Select("cycling.cyclist_name").Columns("id").Selectors(
+				WriteTime("firstname").As("written"),
+				TTLOf("firstname"),
+				Cast("stars", "text").As("stars_text"),
+				TokenOf("id", "firstname").As("Token"),
+			),
+			S: `SELECT id,WRITETIME(firstname) AS written,TTL(firstname),CAST(stars AS text) AS stars_text,token(id,firstname) AS "Token" FROM cycling.cyclist_name `,
+		},
+		// Add function selectors
+		{
+			B: Select("cycling.cyclist_name").Selectors(
+				FnSelect("toJson", FnSelect("toTimestamp", Col("created").As("ignored"))).As("created"),
+				FnSelect("now"),
+			),
+			S: "SELECT toJson(toTimestamp(created)) AS created,now() FROM cycling.cyclist_name ",
+		},
+		// Add collection and UDT selectors
+		{
+			B: Select("cycling.cyclist_name").Selectors(
+				ElemLit("teams", "'2017'").As("team"),
+				SliceLit("events", "1", "3"),
+				SliceLit("events", "", "3"),
+				Field("address", "city").As("city"),
+			).Where(w),
+			S: "SELECT teams['2017'] AS team,events[1..3],events[..3],address.city AS city FROM cycling.cyclist_name WHERE id=? ",
+			N: []string{"expr"},
+		},
+		// Add named element selectors
+		{
+			B: Select("cycling.cyclist_name").Selectors(Elem("teams"), ElemNamed("teams", "year").As("team")).Where(w),
+			S: "SELECT teams[?],teams[?] AS team FROM cycling.cyclist_name WHERE id=? ",
+			N: []string{"teams_key", "year", "expr"},
+		},
+		// Add ORDER BY ANN OF
+		{
+			B: Select("cycling.comments").
+				Columns("id").
+				Selectors(SimilarityCosine("embedding", "vec").As("score")).
+				OrderByANN("embedding", "vec").
+				Limit(10),
+			S: "SELECT id,similarity_cosine(embedding,?) AS score FROM cycling.comments ORDER BY embedding ANN OF ? LIMIT 10 ",
+			N: []string{"vec", "vec"},
+		},
+		// Add similarity selectors
+		{
+			B: Select("cycling.comments").Selectors(SimilarityDotProduct("embedding", "a"), SimilarityEuclidean("embedding", "b")).Where(w),
+			S: "SELECT similarity_dot_product(embedding,?),similarity_euclidean(embedding,?) FROM cycling.comments WHERE id=? ",
+			N: []string{"a", "b", "expr"},
+		},
 		// Add COUNT all
 		{
 			B: Select("cycling.cyclist_name").CountAll().Where(Gt("stars")),
@@ -0,0 +1,137 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+import (
+	"bytes"
+)
+
+// Selectors reference:
+// https://cassandra.apache.org/doc/latest/cql/dml.html#selection-clause
+
+// Selector is a result column of a SELECT statement, i.e. a function call or
+// a collection element. Use As to name the result column so that it can be
+// scanned into a struct field tagged with the alias.
+type Selector struct {
+	value value
+	alias string
+}
+
+// As returns a copy of the selector producing 'selector AS alias'.
+// The alias is quoted if needed to preserve case.
+func (s Selector) As(alias string) Selector {
+	s.alias = alias
+	return s
+}
+
+func (s Selector) writeCql(cql *bytes.Buffer) (names []string) {
+	names = s.value.writeCql(cql)
+	if s.alias != "" {
+		cql.WriteString(" AS ")
+		cql.WriteString(quoteIdentifier(s.alias))
+	}
+	return
+}
+
+// Col produces 'column', it's a selector argument or a column with an alias.
+func Col(column string) Selector {
+	return Selector{value: lit(column)}
+}
+
+// WriteTime produces 'WRITETIME(column)'.
+func WriteTime(column string) Selector {
+	return FnSelect("WRITETIME", Col(column))
+}
+
+// TTLOf produces 'TTL(column)'.
+func TTLOf(column string) Selector {
+	return FnSelect("TTL", Col(column))
+}
+
+// Cast produces 'CAST(column AS typ)'.
+func Cast(column, typ string) Selector {
+	return Selector{value: concat{lit("CAST("), lit(column), lit(" AS "), lit(typ), lit(")")}}
+}
+
+// TokenOf produces 'token(column,...)'.
+func TokenOf(columns ...string) Selector {
+	args := make([]Selector, len(columns))
+	for i, c := range columns {
+		args[i] = Col(c)
+	}
+	return FnSelect("token", args...)
+}
+
+// FnSelect produces 'name(arg,...)', aliases of the arguments are ignored.
+func FnSelect(name string, args ...Selector) Selector {
+	return Selector{value: fnCall{name: name, args: args}}
+}
+
+// Elem produces 'column[?]', it selects a map value or a set element.
+// The key parameter is named column_key, i.e. m_key.
+func Elem(column string) Selector {
+	return ElemNamed(column, elemKey(column))
+}
+
+// ElemNamed produces 'column[?]' with a custom key parameter name.
+func ElemNamed(column, key string) Selector {
+	return Selector{value: elem{column: column, key: param(key)}}
+}
+
+// ElemLit produces 'column[literal]', the literal is written verbatim,
+// i.e. ElemLit("m", "'k'") produces m['k'].
+func ElemLit(column, literal string) Selector {
+	return Selector{value: concat{lit(column), lit("["), lit(literal), lit("]")}}
+}
+
+// SliceLit produces 'column[from..to]', the literals are written verbatim,
+// any of them can be empty to leave the slice open.
+func SliceLit(column, from, to string) Selector {
+	return Selector{value: concat{lit(column), lit("["), lit(from), lit(".."), lit(to), lit("]")}}
+}
+
+// Field produces 'column.field', it selects a single field of a UDT.
+func Field(column, field string) Selector {
+	return Selector{value: lit(column + "." + field)}
+}
+
+// SimilarityCosine produces 'similarity_cosine(column,?)', the cosine
+// similarity of the column vector and the bound vector.
+func SimilarityCosine(column, name string) Selector {
+	return similarity("similarity_cosine", column, name)
+}
+
+// SimilarityDotProduct produces 'similarity_dot_product(column,?)'.
+func SimilarityDotProduct(column, name string) Selector {
+	return similarity("similarity_dot_product", column, name)
+}
+
+// SimilarityEuclidean produces 'similarity_euclidean(column,?)'.
+func SimilarityEuclidean(column, name string) Selector {
+	return similarity("similarity_euclidean", column, name)
+}
+
+func similarity(fn, column, name string) Selector {
+	return FnSelect(fn, Col(column), Selector{value: param(name)})
+}
+
+// fnCall is a function call with selector arguments.
+type fnCall struct {
+	name string
+	args []Selector
+}
+
+func (f fnCall) writeCql(cql *bytes.Buffer) (names []string) {
+	cql.WriteString(f.name)
+	cql.WriteByte('(')
+	for i, a := range f.args {
+		names = append(names, a.value.writeCql(cql)...)
+		if i < len(f.args)-1 {
+			cql.WriteByte(',')
+		}
+	}
+	cql.WriteByte(')')
+	return
+}
@@ -17,16 +17,24 @@ import (
 
 // assignment specifies an assignment in a set operation.
 type assignment struct {
+	target      value // The collection element to set instead of column.
 	column      string
 	value       value
 	valuePrefix string // Tbe value prefix to use for add/remove operations.
+	valueSuffix string // The value suffix to use for prepend operations.
 }
 
 func (a assignment) writeCql(cql *bytes.Buffer) (names []string) {
-	cql.WriteString(a.column)
+	if a.target != nil {
+		names = a.target.writeCql(cql)
+	} else {
+		cql.WriteString(a.column)
+	}
 	cql.WriteByte('=')
 	cql.WriteString(a.valuePrefix)
-	return a.value.writeCql(cql)
+	names = append(names, a.value.writeCql(cql)...)
+	cql.WriteString(a.valueSuffix)
+	return names
 }
 
 // UpdateBuilder builds CQL UPDATE statements.
@@ -92,6 +100,16 @@ func (b *UpdateBuilder) QueryContext(ctx context.Context, session gocqlx.Session
 	return b.Query(session).WithContext(ctx)
 }
 
+// Clone returns a copy of the builder, modifying the copy does not affect
+// the builder. Use it to extend a shared base builder.
+func (b *UpdateBuilder) Clone() *UpdateBuilder {
+	c := *b
+	c.assignments = append([]assignment(nil), b.assignments...)
+	c.where = append(where(nil), b.where...)
+	c._if = append(_if(nil), b._if...)
+	return &c
+}
+
 // Table sets the table to be updated.
 // See the package documentation for table name quoting rules.
 func (b *UpdateBuilder) Table(table string) *UpdateBuilder {
@@ -179,6 +197,40 @@ func (b *UpdateBuilder) SetFunc(column string, fn *Func) *UpdateBuilder {
 	return b
 }
 
+// SetElem adds SET column[?]=? clause to the query, it sets a map value or
+// a list element. The key parameter is named column_key, i.e. m_key.
+func (b *UpdateBuilder) SetElem(column string) *UpdateBuilder {
+	return b.SetElemNamed(column, elemKey(column), column)
+}
+
+// SetElemNamed adds SET column[?]=? clause to the query with custom key and
+// value parameter names.
+func (b *UpdateBuilder) SetElemNamed(column, key, name string) *UpdateBuilder {
+	b.assignments = append(b.assignments, assignment{
+		target: elem{column: column, key: param(key)},
+		column: column,
+		value:  param(name),
+	})
+	return b
+}
+
+// SetField adds SET column.field=? clause to the query, it sets a single
+// field of a UDT. The parameter is named column.field, i.e. addr.city, so
+// that it binds to a field of a nested struct.
+func (b *UpdateBuilder) SetField(column, field string) *UpdateBuilder {
+	return b.SetFieldNamed(column, field, column+"."+field)
+}
+
+// SetFieldNamed adds SET column.field=? clause to the query with a custom
+// parameter name.
+func (b *UpdateBuilder) SetFieldNamed(column, field, name string) *UpdateBuilder {
+	b.assignments = append(b.assignments, assignment{
+		column: column + "." + field,
+		value:  param(name),
+	})
+	return b
+}
+
 // SetTuple adds a SET clause for a tuple to the query.
 func (b *UpdateBuilder) SetTuple(column string, count int) *UpdateBuilder {
 	b.assignments = append(b.assignments, assignment{
@@ -212,6 +264,23 @@ func (b *UpdateBuilder) AddFunc(column string, fn *Func) *UpdateBuilder {
 	return b.addValue(column, fn)
 }
 
+// Prepend adds SET column=?+column clauses to the query, it prepends values
+// to a list.
+func (b *UpdateBuilder) Prepend(column string) *UpdateBuilder {
+	return b.PrependNamed(column, column)
+}
+
+// PrependNamed adds SET column=?+column clauses to the query with a custom
+// parameter name.
+func (b *UpdateBuilder) PrependNamed(column, name string) *UpdateBuilder {
+	b.assignments = append(b.assignments, assignment{
+		column:      column,
+		value:       param(name),
+		valueSuffix: "+" + column,
+	})
+	return b
+}
+
 // AllowFiltering sets a ALLOW FILTERING clause on the query.
 func (b *UpdateBuilder) AllowFiltering() *UpdateBuilder {
 	b.allowFiltering = true
@@ -260,10 +329,15 @@ func (b *UpdateBuilder) removeValue(column string, value value) *UpdateBuilder {
 // Where adds an expression to the WHERE clause of the query. Expressions are
 // ANDed together in the generated CQL.
 func (b *UpdateBuilder) Where(w ...Cmp) *UpdateBuilder {
-	if len(b.where) == 0 {
-		b.where = w
-	} else {
-		b.where = append(b.where, w...)
+	b.where = append(b.where, w...)
+	return b
+}
+
+// WhereIf adds an expression to the WHERE clause of the query if cond is
+// true, i.e. WhereIf(req.Name != "", Eq("name")).
+func (b *UpdateBuilder) WhereIf(cond bool, w ...Cmp) *UpdateBuilder {
+	if cond {
+		b.Where(w...)
 	}
 	return b
 }
@@ -271,11 +345,7 @@ func (b *UpdateBuilder) Where(w ...Cmp) *UpdateBuilder {
 // If adds an expression to the IF clause of the query. Expressions are ANDed
 // together in the generated CQL.
 func (b *UpdateBuilder) If(w ...Cmp) *UpdateBuilder {
-	if len(b._if) == 0 {
-		b._if = w
-	} else {
-		b._if = append(b._if, w...)
-	}
+	b._if = append(b._if, w...)
 	return b
 }
 
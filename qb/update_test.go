@@ -25,6 +25,12 @@ func TestUpdateBuilder(t *testing.T) {
 			S: "UPDATE cycling.cyclist_name SET id=?,user_uuid=?,firstname=? WHERE id=? ",
 			N: []string{"id", "user_uuid", "firstname", "expr"},
 		},
+		// Add WHERE conditionally
+		{
+			B: Update("cycling.cyclist_name").Set("stars").Where(w).WhereIf(false, Eq("firstname")).WhereIf(true, Eq("lastname")),
+			S: "UPDATE cycling.cyclist_name SET stars=? WHERE id=? AND lastname=? ",
+			N: []string{"stars", "expr", "lastname"},
+		},
 		// Change table name
 		{
 			B: Update("cycling.cyclist_name").Set("id", "user_uuid", "firstname").Where(w).Table("Foobar"),
@@ -173,6 +179,42 @@ func TestUpdateBuilder(t *testing.T) {
 			S: "UPDATE cycling.cyclist_name SET timestamp=timestamp-now() ",
 			N: nil,
 		},
+		// Add SET SetElem
+		{
+			B: Update("cycling.cyclist_name").SetElem("teams").Set("stars").Where(w),
+			S: "UPDATE cycling.cyclist_name SET teams[?]=?,stars=? WHERE id=? ",
+			N: []string{"teams_key", "teams", "stars", "expr"},
+		},
+		// Add SET SetElemNamed
+		{
+			B: Update("cycling.cyclist_name").SetElemNamed("teams", "year", "team").Where(w),
+			S: "UPDATE cycling.cyclist_name SET teams[?]=? WHERE id=? ",
+			N: []string{"year", "team", "expr"},
+		},
+		// Add SET SetField
+		{
+			B: Update("cycling.cyclist_name").SetField("address", "city").Where(w),
+			S: "UPDATE cycling.cyclist_name SET address.city=? WHERE id=? ",
+			N: []string{"address.city", "expr"},
+		},
+		// Add SET SetFieldNamed
+		{
+			B: Update("cycling.cyclist_name").SetFieldNamed("address", "city", "city").Where(w),
+			S: "UPDATE cycling.cyclist_name SET address.city=? WHERE id=? ",
+			N: []string{"city", "expr"},
+		},
+		// Add SET Prepend
+		{
+			B: Update("cycling.cyclist_name").Prepend("events").Where(w),
+			S: "UPDATE cycling.cyclist_name SET events=?+events WHERE id=? ",
+			N: []string{"events", "expr"},
+		},
+		// Add SET PrependNamed
+		{
+			B: Update("cycling.cyclist_name").PrependNamed("events", "new_events").Where(w),
+			S: "UPDATE cycling.cyclist_name SET events=?+events WHERE id=? ",
+			N: []string{"new_events", "expr"},
+		},
 		// Add ALLOW FILTERING
 		{
 			B: Update("cycling.cyclist_name").Set("id", "user_uuid", "firstname").Where(w).AllowFiltering(),
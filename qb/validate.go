@@ -0,0 +1,390 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Schema describes a table for Validate. Column names are CQL column names
+// as stored by the server, i.e. lowercase unless created quoted.
+type Schema struct {
+	// Columns are the table columns, key columns may be omitted.
+	Columns []string
+	PartKey []string
+	SortKey []string
+	// Counters are the counter columns.
+	Counters []string
+	// Lists are the non-frozen list columns.
+	Lists []string
+	// Indexed are the columns with a secondary index, restricting them does
+	// not require ALLOW FILTERING.
+	Indexed []string
+}
+
+func (s Schema) has(column string) bool {
+	return slices.Contains(s.Columns, column) || s.isKey(column)
+}
+
+func (s Schema) isKey(column string) bool {
+	return slices.Contains(s.PartKey, column) || slices.Contains(s.SortKey, column)
+}
+
+// Validate checks the statement built by b against the table schema without
+// a cluster. It reports unknown columns, WHERE clauses that don't restrict
+// the full partition key or skip a clustering column, ORDER BY on
+// non-clustering columns and mixing counter and non-counter columns.
+// Restriction checks that can be waived with ALLOW FILTERING are skipped
+// when it's set. All the problems found are returned joined into a single
+// error.
+func Validate(b Builder, s Schema) error {
+	v := validator{schema: s}
+	switch b := b.(type) {
+	case *SelectBuilder:
+		v.validateSelect(b)
+	case *InsertBuilder:
+		v.validateInsert(b)
+	case *UpdateBuilder:
+		v.validateUpdate(b)
+	case *DeleteBuilder:
+		v.validateDelete(b)
+	default:
+		return fmt.Errorf("validate: unsupported builder %T", b)
+	}
+	return errors.Join(v.errs...)
+}
+
+// ValidateCql parses the statement and validates it against the table
+// schema, see Validate. If names are not nil they must match the number
+// of bind markers.
+func ValidateCql(stmt string, names []string, s Schema) error {
+	b, err := Parse(stmt)
+	if err != nil {
+		return err
+	}
+	if names != nil {
+		if _, n := b.ToCql(); len(n) != len(names) {
+			return fmt.Errorf("validate: got %d names for %d bind markers", len(names), len(n))
+		}
+	}
+	return Validate(b, s)
+}
+
+type validator struct {
+	schema Schema
+	errs   []error
+}
+
+func (v *validator) errorf(format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func (v *validator) known(columns ...string) {
+	for _, c := range columns {
+		if !v.schema.has(c) {
+			v.errorf("unknown column %q", c)
+		}
+	}
+}
+
+func (v *validator) validateSelect(b *SelectBuilder) {
+	for _, c := range b.columns {
+		v.known(columnsOf(c)...)
+	}
+	for _, c := range b.distinct {
+		v.known(columnsOf(lit(c))...)
+	}
+	for _, c := range b.groupBy {
+		v.known(columnsOf(lit(c))...)
+	}
+	for _, o := range b.orderBy {
+		v.validateOrderBy(o)
+	}
+
+	r := v.restrictions(b.where)
+	if b.allowFiltering {
+		return
+	}
+	v.validatePartKey(r, false)
+	v.validateSortKey(r, false)
+	for _, c := range r.other {
+		if !slices.Contains(v.schema.Indexed, c) {
+			v.errorf("restricting non primary key column %q requires ALLOW FILTERING", c)
+		}
+	}
+}
+
+func (v *validator) validateOrderBy(o value) {
+	var cql bytes.Buffer
+	o.writeCql(&cql)
+	toks, err := lex(cql.String())
+	if err != nil || len(toks) == 0 {
+		return
+	}
+	column := columnName(toks[0].text)
+	v.known(column)
+	if len(toks) > 1 && toks[1].is("ANN") {
+		return
+	}
+	if v.schema.has(column) && !slices.Contains(v.schema.SortKey, column) {
+		v.errorf("ORDER BY on non clustering column %q", column)
+	}
+}
+
+func (v *validator) validateInsert(b *InsertBuilder) {
+	if b.json {
+		return
+	}
+	var columns []string
+	for _, c := range b.columns {
+		column := columnName(c.column)
+		columns = append(columns, column)
+		v.known(column)
+		if slices.Contains(v.schema.Counters, column) {
+			v.errorf("counter column %q can't be inserted, use UPDATE", column)
+		}
+	}
+	for _, k := range slices.Concat(v.schema.PartKey, v.schema.SortKey) {
+		if !slices.Contains(columns, k) {
+			v.errorf("primary key column %q is missing", k)
+		}
+	}
+}
+
+func (v *validator) validateUpdate(b *UpdateBuilder) {
+	var counters, regular []string
+	for _, a := range b.assignments {
+		columns := columnsOf(lit(a.column))
+		if a.target != nil {
+			columns = columnsOf(a.target)
+		}
+		v.known(columns...)
+		for _, c := range columns {
+			if slices.Contains(v.schema.Counters, c) {
+				counters = append(counters, c)
+			} else {
+				regular = append(regular, c)
+			}
+		}
+	}
+	if len(counters) > 0 && len(regular) > 0 {
+		v.errorf("counter columns %q can't be updated with non-counter columns %q", counters, regular)
+	}
+	for _, c := range b._if {
+		v.known(relationColumns(c.column)...)
+	}
+
+	r := v.restrictions(b.where)
+	v.validatePartKey(r, true)
+	v.validateSortKey(r, true)
+	for _, c := range r.other {
+		v.errorf("non primary key column %q can't be restricted", c)
+	}
+}
+
+func (v *validator) validateDelete(b *DeleteBuilder) {
+	for _, c := range b.columns {
+		v.known(columnsOf(c)...)
+	}
+	for _, c := range b._if {
+		v.known(relationColumns(c.column)...)
+	}
+
+	r := v.restrictions(b.where)
+	v.validatePartKey(r, true)
+	v.validateSortKey(r, false)
+	for _, c := range r.other {
+		v.errorf("non primary key column %q can't be restricted", c)
+	}
+}
+
+// restrictions holds WHERE clause restrictions by column.
+type restrictions struct {
+	ops   map[string]op
+	token bool
+	other []string
+}
+
+func (v *validator) restrictions(w where) restrictions {
+	r := restrictions{ops: make(map[string]op)}
+	for _, c := range w {
+		columns := relationColumns(c.column)
+		v.known(columns...)
+		if strings.HasPrefix(strings.ToLower(c.column), "token(") {
+			r.token = true
+			if !slices.Equal(columns, v.schema.PartKey) {
+				v.errorf("token() must use partition key columns %q", v.schema.PartKey)
+			}
+			continue
+		}
+		if strings.HasPrefix(c.column, "(") {
+			v.multiColumn(r, columns, c.op)
+			continue
+		}
+		for _, col := range columns {
+			if !v.schema.isKey(col) {
+				if v.schema.has(col) {
+					r.other = append(r.other, col)
+				}
+				continue
+			}
+			r.ops[col] = c.op
+		}
+	}
+	return r
+}
+
+// multiColumn adds restrictions of a multi-column relation i.e.
+// (ck1,ck2)>(?,?). It restricts consecutive clustering columns, all but the
+// last one are restricted as if with =, so that a range is only at the last
+// column.
+func (v *validator) multiColumn(r restrictions, columns []string, o op) {
+	prev := -1
+	for i, col := range columns {
+		k := slices.Index(v.schema.SortKey, col)
+		if k < 0 {
+			if v.schema.has(col) {
+				v.errorf("multi-column relation on non clustering column %q", col)
+			}
+			return
+		}
+		if prev >= 0 && k != prev+1 {
+			v.errorf("multi-column relation columns %q are not consecutive clustering columns", columns)
+			return
+		}
+		prev = k
+
+		if i < len(columns)-1 && o != in {
+			r.ops[col] = eq
+		} else {
+			r.ops[col] = o
+		}
+	}
+}
+
+// validatePartKey checks that the partition key is either fully restricted
+// with = or IN or not restricted at all unless required.
+func (v *validator) validatePartKey(r restrictions, required bool) {
+	var missing []string
+	restricted := false
+	for _, k := range v.schema.PartKey {
+		op, ok := r.ops[k]
+		if !ok {
+			missing = append(missing, k)
+			continue
+		}
+		restricted = true
+		if op != eq && op != in {
+			v.errorf("partition key column %q can only be restricted with = or IN", k)
+		}
+	}
+	if len(missing) == 0 || r.token && !required {
+		return
+	}
+	if restricted || required {
+		v.errorf("partition key columns %q are not restricted", missing)
+	} else if len(r.ops) > 0 {
+		v.errorf("restricting clustering columns without the partition key requires ALLOW FILTERING")
+	}
+}
+
+// validateSortKey checks that the restricted clustering columns form
+// a prefix of the clustering key and that only the last one is a range.
+// If full is set all the clustering columns must be restricted.
+func (v *validator) validateSortKey(r restrictions, full bool) {
+	prev := ""
+	for i, k := range v.schema.SortKey {
+		op, ok := r.ops[k]
+		if !ok {
+			if full {
+				v.errorf("clustering column %q is not restricted", k)
+				return
+			}
+			for _, next := range v.schema.SortKey[i+1:] {
+				if _, ok := r.ops[next]; ok {
+					v.errorf("clustering column %q is restricted but preceding column %q is not", next, k)
+					return
+				}
+			}
+			return
+		}
+		if prev != "" {
+			v.errorf("clustering column %q is restricted after a range restriction on %q", k, prev)
+			return
+		}
+		if op != eq && op != in {
+			prev = k
+		}
+	}
+}
+
+// relationColumns returns columns of a relation, i.e. id, (a,b) or token(id).
+func relationColumns(column string) []string {
+	toks, err := lex(column)
+	if err != nil || len(toks) == 0 {
+		return nil
+	}
+	if toks[0].is("TOKEN") || toks[0].is("(") {
+		var columns []string
+		for _, t := range toks {
+			if (t.kind == tokIdent || t.kind == tokQuoted) && !t.is("TOKEN") {
+				columns = append(columns, columnName(t.text))
+			}
+		}
+		return columns
+	}
+	return []string{columnName(toks[0].text)}
+}
+
+// columnsOf returns columns referenced by a selector or an element, function
+// names, aliases, types, UDT fields and literals are skipped.
+func columnsOf(v value) []string {
+	var cql bytes.Buffer
+	v.writeCql(&cql)
+	toks, err := lex(cql.String())
+	if err != nil {
+		return nil
+	}
+
+	var columns []string
+	for i, t := range toks {
+		if t.kind != tokIdent && t.kind != tokQuoted {
+			continue
+		}
+		if i+1 < len(toks) && toks[i+1].is("(") {
+			continue
+		}
+		if i > 0 && (toks[i-1].is("AS") || toks[i-1].is(".")) {
+			continue
+		}
+		if t.kind == tokIdent && isReserved(t.text) {
+			continue
+		}
+		columns = append(columns, columnName(t.text))
+	}
+	return columns
+}
+
+func isReserved(s string) bool {
+	for _, k := range []string{"AS", "DISTINCT", "TRUE", "FALSE", "NULL", "NAN", "INFINITY"} {
+		if strings.EqualFold(s, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// columnName returns the column name as stored by the server, unquoted
+// identifiers are case insensitive.
+func columnName(identifier string) string {
+	if strings.HasPrefix(identifier, `"`) {
+		return unquote(identifier)
+	}
+	return strings.ToLower(identifier)
+}
@@ -0,0 +1,217 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package qb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	s := Schema{
+		Columns: []string{"id", "bucket", "ts", "seq", "name", "tags", "m", "addr", "Mixed", "embedding"},
+		PartKey: []string{"id", "bucket"},
+		SortKey: []string{"ts", "seq"},
+		Indexed: []string{"name"},
+	}
+	counters := Schema{
+		Columns:  []string{"id", "views", "likes", "note"},
+		PartKey:  []string{"id"},
+		Counters: []string{"views", "likes"},
+	}
+	key := []Cmp{Eq("id"), Eq("bucket")}
+
+	table := []struct {
+		B      Builder
+		S      Schema
+		Errors []string
+	}{
+		// Select
+		{
+			B: Select("t").Columns("id", "name AS n", `"Mixed"`).Selectors(WriteTime("name"), Elem("m"), Field("addr", "city"), Cast("ts", "text")).
+				Where(key...).Where(Eq("ts"), Gt("seq")).OrderBy("ts", DESC),
+			S: s,
+		},
+		{
+			B: Select("t").Columns("ID").Where(EqNamed("Id", "x"), In("bucket")),
+			S: s,
+		},
+		{
+			B: Select("t").Where(Token("id", "bucket").Gt()).Limit(10),
+			S: s,
+		},
+		{
+			B: Select("t").Where(Eq("name")),
+			S: s,
+		},
+		{
+			B: Select("t").Columns("id").OrderByANN("embedding", "v").Limit(10),
+			S: s,
+		},
+		{
+			B:      Select("t").Columns("nmae", "mixed").Where(Eq("idd")),
+			S:      s,
+			Errors: []string{`unknown column "nmae"`, `unknown column "mixed"`, `unknown column "idd"`},
+		},
+		{
+			B:      Select("t").Where(Eq("id")),
+			S:      s,
+			Errors: []string{`partition key columns ["bucket"] are not restricted`},
+		},
+		{
+			B: Select("t").Where(Eq("id")).AllowFiltering(),
+			S: s,
+		},
+		{
+			B:      Select("t").Where(Eq("tags")),
+			S:      s,
+			Errors: []string{`restricting non primary key column "tags" requires ALLOW FILTERING`},
+		},
+		{
+			B:      Select("t").Where(Eq("ts")),
+			S:      s,
+			Errors: []string{`restricting clustering columns without the partition key requires ALLOW FILTERING`},
+		},
+		{
+			B:      Select("t").Where(key...).Where(Eq("seq")),
+			S:      s,
+			Errors: []string{`clustering column "seq" is restricted but preceding column "ts" is not`},
+		},
+		{
+			B:      Select("t").Where(key...).Where(Gt("ts"), Eq("seq")),
+			S:      s,
+			Errors: []string{`clustering column "seq" is restricted after a range restriction on "ts"`},
+		},
+		{
+			B:      Select("t").Where(key...).OrderBy("name", ASC),
+			S:      s,
+			Errors: []string{`ORDER BY on non clustering column "name"`},
+		},
+		{
+			B:      Select("t").Where(Token("id").Gt()),
+			S:      s,
+			Errors: []string{`token() must use partition key columns ["id" "bucket"]`},
+		},
+
+		{
+			B: Select("t").Where(key...).Where(MultiColumn("ts", "seq").Gt(), MultiColumn("ts", "seq").LtOrEq()),
+			S: s,
+		},
+		{
+			B: Select("t").Where(key...).Where(GtTuple("(ts,seq)", 2), MultiColumn("ts", "seq").In(2)),
+			S: s,
+		},
+		{
+			B:      Select("t").Where(key...).Where(MultiColumn("ts").Gt(), Eq("seq")),
+			S:      s,
+			Errors: []string{`clustering column "seq" is restricted after a range restriction on "ts"`},
+		},
+		{
+			B:      Select("t").Where(key...).Where(MultiColumn("seq").Gt()),
+			S:      s,
+			Errors: []string{`clustering column "seq" is restricted but preceding column "ts" is not`},
+		},
+		{
+			B:      Select("t").Where(key...).Where(MultiColumn("seq", "ts").Gt(), MultiColumn("ts", "name").Eq()),
+			S:      s,
+			Errors: []string{`multi-column relation columns ["seq" "ts"] are not consecutive clustering columns`, `multi-column relation on non clustering column "name"`},
+		},
+		{
+			B: Delete("t").Where(key...).Where(MultiColumn("ts", "seq").Eq()),
+			S: s,
+		},
+
+		// Insert
+		{
+			B: Insert("t").Columns("id", "bucket", "ts", "seq", "name"),
+			S: s,
+		},
+		{
+			B: Insert("t").Json(),
+			S: s,
+		},
+		{
+			B:      Insert("t").Columns("id", "bucket", "ts", "nmae"),
+			S:      s,
+			Errors: []string{`unknown column "nmae"`, `primary key column "seq" is missing`},
+		},
+		{
+			B:      Insert("t").Columns("id", "views"),
+			S:      counters,
+			Errors: []string{`counter column "views" can't be inserted, use UPDATE`},
+		},
+
+		// Update
+		{
+			B: Update("t").Set("name").SetElem("m").SetField("addr", "city").Add("tags").Where(key...).Where(Eq("ts"), In("seq")).If(Eq("name")),
+			S: s,
+		},
+		{
+			B: Update("t").Add("views").Remove("likes").Where(Eq("id")),
+			S: counters,
+		},
+		{
+			B:      Update("t").Set("nmae").Where(key...).Where(Eq("ts")).If(Eq("tgas")),
+			S:      s,
+			Errors: []string{`unknown column "nmae"`, `unknown column "tgas"`, `clustering column "seq" is not restricted`},
+		},
+		{
+			B:      Update("t").Set("name").Where(Eq("id"), Eq("ts"), Eq("seq"), Eq("tags")),
+			S:      s,
+			Errors: []string{`partition key columns ["bucket"] are not restricted`, `non primary key column "tags" can't be restricted`},
+		},
+		{
+			B:      Update("t").Add("views").Set("note").Where(Eq("id")),
+			S:      counters,
+			Errors: []string{`counter columns ["views"] can't be updated with non-counter columns ["note"]`},
+		},
+
+		// Delete
+		{
+			B: Delete("t").Columns("name").ColumnElem("m").Where(key...).Where(Gt("ts")),
+			S: s,
+		},
+		{
+			B:      Delete("t").Where(Gt("id"), Eq("bucket"), Eq("seq")),
+			S:      s,
+			Errors: []string{`partition key column "id" can only be restricted with = or IN`, `clustering column "seq" is restricted but preceding column "ts" is not`},
+		},
+	}
+
+	for _, test := range table {
+		stmt, _ := test.B.ToCql()
+		t.Run(stmt, func(t *testing.T) {
+			var errs []string
+			if err := Validate(test.B, test.S); err != nil {
+				errs = strings.Split(err.Error(), "\n")
+			}
+			if diff := cmp.Diff(test.Errors, errs); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestValidateCql(t *testing.T) {
+	s := Schema{
+		Columns: []string{"id", "name"},
+		PartKey: []string{"id"},
+	}
+
+	if err := ValidateCql("SELECT name FROM t WHERE id=:id", []string{"id"}, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateCql("SELECT name FROM t WHERE id=?", []string{"id", "name"}, s); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := ValidateCql("SELECT nmae FROM t WHERE id=?", nil, s); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := ValidateCql("SELECT name FROM", nil, s); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -47,6 +47,48 @@ func (t tupleParam) writeCql(cql *bytes.Buffer) (names []string) {
 	return
 }
 
+// elem is a collection element column[?] with a named key parameter.
+type elem struct {
+	key    value
+	column string
+}
+
+func (e elem) writeCql(cql *bytes.Buffer) (names []string) {
+	cql.WriteString(e.column)
+	cql.WriteByte('[')
+	names = e.key.writeCql(cql)
+	cql.WriteByte(']')
+	return
+}
+
+// elemKey returns the default name of the element key parameter of column.
+func elemKey(column string) string {
+	return column + "_key"
+}
+
+// concat is a value made of consecutive values.
+type concat []value
+
+func (c concat) writeCql(cql *bytes.Buffer) (names []string) {
+	for _, v := range c {
+		names = append(names, v.writeCql(cql)...)
+	}
+	return
+}
+
+// valueList is a comma separated list of values.
+type valueList []value
+
+func (l valueList) writeCql(cql *bytes.Buffer) (names []string) {
+	for i, v := range l {
+		names = append(names, v.writeCql(cql)...)
+		if i < len(l)-1 {
+			cql.WriteByte(',')
+		}
+	}
+	return
+}
+
 // lit is a literal CQL value.
 type lit string
 
@@ -93,10 +93,22 @@ func allowedBindRune(b byte) bool {
 type Queryx struct {
 	err    error
 	tr     Transformer
+	scanTr ScanTransformer
 	Mapper *reflectx.Mapper
+	Codecs *CodecRegistry
 	*gocql.Query
-	Names  []string
-	strict bool
+	Names []string
+
+	// ExpandIn parameter names, names of the bound values and the statement
+	// before expansion.
+	expandIn      []string
+	expandedNames []string
+	stmt          string
+	// settings are the query settings applied again by setStatement.
+	settings []func(qry *gocql.Query)
+
+	inspector Inspector
+	strict    bool
 }
 
 // Query creates a new Queryx from gocql.Query using a default mapper.
@@ -108,6 +120,7 @@ func Query(q *gocql.Query, names []string) *Queryx {
 		Names:  names,
 		Mapper: DefaultMapper,
 		tr:     DefaultBindTransformer,
+		scanTr: DefaultScanTransformer,
 		strict: DefaultStrict,
 	}
 }
@@ -119,15 +132,33 @@ func (q *Queryx) WithBindTransformer(tr Transformer) *Queryx {
 	return q
 }
 
+// WithScanTransformer sets the query scan transformer.
+// The transformer is called for every column right after it's scanned by
+// Get, Select, Scan, MapScan, the CAS variants or the iterator.
+func (q *Queryx) WithScanTransformer(tr ScanTransformer) *Queryx {
+	q.scanTr = tr
+	return q
+}
+
+// WithColumnCodecs adds the codecs to the query bind and scan transformers,
+// they are applied after the transformers already set i.e. by the session.
+func (q *Queryx) WithColumnCodecs(c ColumnCodecs) *Queryx {
+	q.tr = ChainTransformers(q.tr, c.BindTransformer())
+	q.scanTr = ChainScanTransformers(q.scanTr, c.ScanTransformer())
+	return q
+}
+
 // BindStruct binds query named parameters to values from arg using mapper. If
 // value cannot be found error is reported.
 func (q *Queryx) BindStruct(arg interface{}) *Queryx {
 	arglist, err := q.bindStructArgs(arg, nil)
+	if err == nil {
+		err = q.bindExpanded(arglist)
+	}
 	if err != nil {
 		q.err = fmt.Errorf("bind error: %s", err)
 	} else {
 		q.err = nil
-		q.Bind(arglist...)
 	}
 
 	return q
@@ -138,11 +169,13 @@ func (q *Queryx) BindStruct(arg interface{}) *Queryx {
 // before reporting an error.
 func (q *Queryx) BindStructMap(arg0 interface{}, arg1 map[string]interface{}) *Queryx {
 	arglist, err := q.bindStructArgs(arg0, arg1)
+	if err == nil {
+		err = q.bindExpanded(arglist)
+	}
 	if err != nil {
 		q.err = fmt.Errorf("bind error: %s", err)
 	} else {
 		q.err = nil
-		q.Bind(arglist...)
 	}
 
 	return q
@@ -179,9 +212,14 @@ func (q *Queryx) bindStructArgs(arg0 interface{}, arg1 map[string]interface{}) (
 		v = v.Elem()
 	}
 
+	tm := q.Mapper.TypeMap(v.Type())
 	err := q.Mapper.TraversalsByNameFunc(v.Type(), q.Names, func(i int, t []int) error {
 		if len(t) != 0 {
 			val := reflectx.FieldByIndexesReadOnly(v, t)
+			if _, ok := tm.Names[q.Names[i]].Options[OmitEmptyOption]; ok && val.IsZero() {
+				arglist = append(arglist, gocql.UnsetValue)
+				return nil
+			}
 			arglist = append(arglist, val.Interface())
 		} else {
 			val, ok := arg1[q.Names[i]]
@@ -194,6 +232,7 @@ func (q *Queryx) bindStructArgs(arg0 interface{}, arg1 map[string]interface{}) (
 		if q.tr != nil {
 			arglist[i] = q.tr(q.Names[i], arglist[i])
 		}
+		arglist[i] = q.Codecs.wrap(arglist[i])
 
 		return nil
 	})
@@ -204,11 +243,13 @@ func (q *Queryx) bindStructArgs(arg0 interface{}, arg1 map[string]interface{}) (
 // BindMap binds query named parameters using map.
 func (q *Queryx) BindMap(arg map[string]interface{}) *Queryx {
 	arglist, err := q.bindMapArgs(arg)
+	if err == nil {
+		err = q.bindExpanded(arglist)
+	}
 	if err != nil {
 		q.err = fmt.Errorf("bind error: %s", err)
 	} else {
 		q.err = nil
-		q.Bind(arglist...)
 	}
 
 	return q
@@ -226,23 +267,91 @@ func (q *Queryx) bindMapArgs(arg map[string]interface{}) ([]interface{}, error)
 		if q.tr != nil {
 			val = q.tr(name, val)
 		}
-		arglist = append(arglist, val)
+		arglist = append(arglist, q.Codecs.wrap(val))
 	}
 	return arglist, nil
 }
 
 // Bind sets query arguments of query. This can also be used to rebind new query arguments
-// to an existing query instance.
+// to an existing query instance. If ExpandIn is used there must be an argument
+// for every name.
 func (q *Queryx) Bind(v ...interface{}) *Queryx {
-	q.Query.Bind(udtWrapSlice(q.Mapper, q.strict, v)...)
+	if len(q.expandIn) > 0 {
+		if err := q.bindExpanded(v); err != nil {
+			q.err = fmt.Errorf("bind error: %s", err)
+		} else {
+			q.err = nil
+		}
+		return q
+	}
+	q.bind(v)
 	return q
 }
 
+func (q *Queryx) bind(v []interface{}) {
+	q.Query.Bind(udtWrapSlice(q.Mapper, q.Codecs, q.strict, v)...)
+}
+
 // Scan executes the query, copies the columns of the first selected
 // row into the values pointed at by dest and discards the rest. If no rows
 // were selected, ErrNotFound is returned.
 func (q *Queryx) Scan(v ...interface{}) error {
-	return q.Query.Scan(udtWrapSlice(q.Mapper, q.strict, v)...)
+	iter := q.Iter()
+	if err := iter.checkErrAndNotFound(); err != nil {
+		iter.Close()
+		return err
+	}
+	iter.Scan(v...)
+	return iter.Close()
+}
+
+// MapScan executes the query, copies the columns of the first selected row
+// into the map and discards the rest. If no rows were selected, ErrNotFound
+// is returned.
+func (q *Queryx) MapScan(m map[string]interface{}) error {
+	iter := q.Iter()
+	if err := iter.checkErrAndNotFound(); err != nil {
+		iter.Close()
+		return err
+	}
+	iter.MapScan(m)
+	return iter.Close()
+}
+
+// ScanCAS executes the Lightweight Transaction query, if it's not applied
+// the previous values are stored in dest. See gocql.Query.ScanCAS.
+func (q *Queryx) ScanCAS(dest ...interface{}) (applied bool, err error) {
+	q.NoSkipMetadata()
+	iter := q.Iter()
+	if err := iter.checkErrAndNotFound(); err != nil {
+		iter.Close()
+		return false, err
+	}
+	if len(iter.Columns()) > 1 {
+		iter.Scan(append([]interface{}{&applied}, dest...)...)
+	} else {
+		iter.Scan(&applied)
+	}
+	return applied, iter.Close()
+}
+
+// MapScanCAS executes the Lightweight Transaction query, if it's not applied
+// the previous values are stored in dest. See gocql.Query.MapScanCAS.
+func (q *Queryx) MapScanCAS(dest map[string]interface{}) (applied bool, err error) {
+	q.NoSkipMetadata()
+	iter := q.Iter()
+	if err := iter.checkErrAndNotFound(); err != nil {
+		iter.Close()
+		return false, err
+	}
+	if !iter.MapScan(dest) {
+		return false, iter.Close()
+	}
+	if v, ok := dest[appliedColumn]; ok {
+		applied, _ = v.(bool)
+		delete(dest, appliedColumn)
+	}
+	return applied, iter.Close()
 }
 
 // Err returns any binding errors.
@@ -255,6 +364,7 @@ func (q *Queryx) Exec() error {
 	if q.err != nil {
 		return q.err
 	}
+	q.inspect()
 	return q.Query.Exec()
 }
 
@@ -371,13 +481,22 @@ func (q *Queryx) SelectRelease(dest interface{}) error {
 // big to be loaded with Select in order to do row by row iteration.
 // See Iterx StructScan function.
 func (q *Queryx) Iter() *Iterx {
+	q.inspect()
 	return &Iterx{
 		Iter:   q.Query.Iter(),
 		Mapper: q.Mapper,
+		Codecs: q.Codecs,
+		tr:     q.scanTr,
 		strict: q.strict,
 	}
 }
 
+func (q *Queryx) inspect() {
+	if q.inspector != nil {
+		q.inspector.InspectQuery(q)
+	}
+}
+
 // Strict forces the query and iterators to report an error if there are missing fields.
 // By default when scanning a struct if result row has a column that cannot be mapped to
 // any destination it is ignored. With strict error is reported.
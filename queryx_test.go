@@ -164,6 +164,33 @@ func TestQueryxBindStruct(t *testing.T) {
 	})
 }
 
+func TestQueryxBindStructTagOptions(t *testing.T) {
+	v := &struct {
+		Name    string
+		Age     int    `db:"age,omitempty"`
+		Email   string `db:"email,omitempty"`
+		Note    string
+		Skipped string `db:"-"`
+	}{
+		Name:    "name",
+		Email:   "name@example.com",
+		Skipped: "skipped",
+	}
+
+	names := []string{"name", "age", "email", "note"}
+	args, err := Query(nil, names).bindStructArgs(v, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(args, []interface{}{"name", gocql.UnsetValue, "name@example.com", ""}); diff != "" {
+		t.Error("args mismatch", diff)
+	}
+
+	if _, err := Query(nil, []string{"skipped"}).bindStructArgs(v, nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
 func TestQueryxBindMap(t *testing.T) {
 	v := map[string]interface{}{
 		"name":  "name",
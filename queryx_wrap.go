@@ -12,6 +12,14 @@ import (
 
 // This file contains wrappers around gocql.Query that make Queryx expose the
 // same interface but return *Queryx, this should be inlined by compiler.
+// Settings without a gocql.Query getter are recorded with set so that they
+// can be applied again when ExpandIn replaces the query.
+
+// set applies fn to the query and records it for setStatement.
+func (q *Queryx) set(fn func(qry *gocql.Query)) {
+	fn(q.Query)
+	q.settings = append(q.settings, fn)
+}
 
 // Consistency sets the consistency level for this query. If no consistency
 // level have been set, the default consistency level of the cluster
@@ -23,21 +31,21 @@ func (q *Queryx) Consistency(c gocql.Consistency) *Queryx {
 
 // CustomPayload sets the custom payload level for this query.
 func (q *Queryx) CustomPayload(customPayload map[string][]byte) *Queryx {
-	q.Query.CustomPayload(customPayload)
+	q.set(func(qry *gocql.Query) { qry.CustomPayload(customPayload) })
 	return q
 }
 
 // Trace enables tracing of this query. Look at the documentation of the
 // Tracer interface to learn more about tracing.
 func (q *Queryx) Trace(trace gocql.Tracer) *Queryx {
-	q.Query.Trace(trace)
+	q.set(func(qry *gocql.Query) { qry.Trace(trace) })
 	return q
 }
 
 // Observer enables query-level observer on this query.
 // The provided observer will be called every time this query is executed.
 func (q *Queryx) Observer(observer gocql.QueryObserver) *Queryx {
-	q.Query.Observer(observer)
+	q.set(func(qry *gocql.Query) { qry.Observer(observer) })
 	return q
 }
 
@@ -46,7 +54,7 @@ func (q *Queryx) Observer(observer gocql.QueryObserver) *Queryx {
 // page size too low might decrease the performance. This feature is only
 // available in Cassandra 2 and onwards.
 func (q *Queryx) PageSize(n int) *Queryx {
-	q.Query.PageSize(n)
+	q.set(func(qry *gocql.Query) { qry.PageSize(n) })
 	return q
 }
 
@@ -57,7 +65,7 @@ func (q *Queryx) PageSize(n int) *Queryx {
 //
 // Only available on protocol >= 3
 func (q *Queryx) DefaultTimestamp(enable bool) *Queryx {
-	q.Query.DefaultTimestamp(enable)
+	q.set(func(qry *gocql.Query) { qry.DefaultTimestamp(enable) })
 	return q
 }
 
@@ -68,14 +76,14 @@ func (q *Queryx) DefaultTimestamp(enable bool) *Queryx {
 //
 // Only available on protocol >= 3
 func (q *Queryx) WithTimestamp(timestamp int64) *Queryx {
-	q.Query.WithTimestamp(timestamp)
+	q.set(func(qry *gocql.Query) { qry.WithTimestamp(timestamp) })
 	return q
 }
 
 // RoutingKey sets the routing key to use when a token aware connection
 // pool is used to optimize the routing of this query.
 func (q *Queryx) RoutingKey(routingKey []byte) *Queryx {
-	q.Query.RoutingKey(routingKey)
+	q.set(func(qry *gocql.Query) { qry.RoutingKey(routingKey) })
 	return q
 }
 
@@ -97,19 +105,19 @@ func (q *Queryx) WithContext(ctx context.Context) *Queryx {
 // there are only p*pageSize rows remaining, the next page will be requested
 // automatically.
 func (q *Queryx) Prefetch(p float64) *Queryx {
-	q.Query.Prefetch(p)
+	q.set(func(qry *gocql.Query) { qry.Prefetch(p) })
 	return q
 }
 
 // RetryPolicy sets the policy to use when retrying the query.
 func (q *Queryx) RetryPolicy(r gocql.RetryPolicy) *Queryx {
-	q.Query.RetryPolicy(r)
+	q.set(func(qry *gocql.Query) { qry.RetryPolicy(r) })
 	return q
 }
 
 // SetSpeculativeExecutionPolicy sets the execution policy.
 func (q *Queryx) SetSpeculativeExecutionPolicy(sp gocql.SpeculativeExecutionPolicy) *Queryx {
-	q.Query.SetSpeculativeExecutionPolicy(sp)
+	q.set(func(qry *gocql.Query) { qry.SetSpeculativeExecutionPolicy(sp) })
 	return q
 }
 
@@ -126,7 +134,7 @@ func (q *Queryx) Idempotent(value bool) *Queryx {
 // SERIAL. This option will be ignored for anything else that a
 // conditional update/insert.
 func (q *Queryx) SerialConsistency(cons gocql.SerialConsistency) *Queryx {
-	q.Query.SerialConsistency(cons)
+	q.set(func(qry *gocql.Query) { qry.SerialConsistency(cons) })
 	return q
 }
 
@@ -134,7 +142,7 @@ func (q *Queryx) SerialConsistency(cons gocql.SerialConsistency) *Queryx {
 // point in time. Setting this will disable to query paging for this query, and
 // must be used for all subsequent pages.
 func (q *Queryx) PageState(state []byte) *Queryx {
-	q.Query.PageState(state)
+	q.set(func(qry *gocql.Query) { qry.PageState(state) })
 	return q
 }
 
@@ -147,6 +155,6 @@ func (q *Queryx) PageState(state []byte) *Queryx {
 // See https://issues.apache.org/jira/browse/CASSANDRA-11099
 // https://github.com/gocql/gocql/issues/612
 func (q *Queryx) NoSkipMetadata() *Queryx {
-	q.Query.NoSkipMetadata()
+	q.set(func(qry *gocql.Query) { qry.NoSkipMetadata() })
 	return q
 }
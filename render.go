@@ -0,0 +1,670 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package gocqlx
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/scylladb/go-reflectx"
+	"gopkg.in/inf.v0"
+)
+
+// RedactedLiteral replaces values of redacted parameters in rendered
+// statements.
+const RedactedLiteral = "'***'"
+
+// Renderer renders statements with bound values inlined as CQL literals,
+// i.e. for logs, reproducing a query in cqlsh or generating scripts.
+type Renderer struct {
+	// Types maps parameter names to CQL types. Values of parameters with
+	// a type are marshalled and rendered as that type, this renders exact
+	// literals for values that can be bound to many types, i.e. a slice
+	// bound to a set or a time.Time bound to a date. Values of types with
+	// a codec can only be rendered with a type. Other values are rendered
+	// based on their Go type.
+	Types map[string]gocql.TypeInfo
+	// Redact lists names of parameters, which values are rendered as
+	// RedactedLiteral. A name also redacts tuple elements and UDT fields
+	// bound to it, i.e. password redacts password[0] and password.hash.
+	Redact []string
+}
+
+// Render returns stmt with '?' placeholders replaced with CQL literals of
+// the values. Names are the names of the values and may be nil if no types
+// nor redactions are set. Unset values are rendered as null.
+func (r Renderer) Render(stmt string, names []string, values []interface{}) (string, error) {
+	if names != nil && len(names) != len(values) {
+		return "", fmt.Errorf("got %d names and %d values", len(names), len(values))
+	}
+
+	offsets := placeholders(stmt)
+	if len(offsets) != len(values) {
+		return "", fmt.Errorf("statement has %d placeholders, got %d values", len(offsets), len(values))
+	}
+
+	var (
+		buf  strings.Builder
+		last int
+	)
+	for i, off := range offsets {
+		buf.WriteString(stmt[last:off])
+		last = off + 1
+
+		var name string
+		if names != nil {
+			name = names[i]
+		}
+		if r.redacted(name) {
+			buf.WriteString(RedactedLiteral)
+			continue
+		}
+
+		var err error
+		in := afterIn(stmt[:off])
+		if info, ok := r.Types[name]; ok && name != "" {
+			err = writeTypedLiteral(&buf, info, values[i], in)
+		} else {
+			err = writeLiteral(&buf, values[i], in)
+		}
+		if err != nil {
+			if name != "" {
+				return "", fmt.Errorf("render %q: %w", name, err)
+			}
+			return "", fmt.Errorf("render value %d: %w", i, err)
+		}
+	}
+	buf.WriteString(stmt[last:])
+
+	return buf.String(), nil
+}
+
+// RenderQuery renders the statement of q with the bound values inlined, it
+// returns the binding error of q if any.
+func (r Renderer) RenderQuery(q *Queryx) (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+	names := q.Names
+	if q.expandedNames != nil {
+		names = q.expandedNames
+	}
+	return r.Render(q.Query.Statement(), names, q.Query.Values())
+}
+
+func (r Renderer) redacted(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, v := range r.Redact {
+		if name == v {
+			return true
+		}
+		if strings.HasPrefix(name, v) && (name[len(v)] == '[' || name[len(v)] == '.') {
+			return true
+		}
+	}
+	return false
+}
+
+// Render is a shorthand for Renderer{}.Render.
+func Render(stmt string, names []string, values []interface{}) (string, error) {
+	return Renderer{}.Render(stmt, names, values)
+}
+
+// Literal returns v as a CQL literal. If info is nil v is rendered based on
+// its Go type.
+func Literal(info gocql.TypeInfo, v interface{}) (string, error) {
+	var (
+		buf strings.Builder
+		err error
+	)
+	if info != nil {
+		err = writeTypedLiteral(&buf, info, v, false)
+	} else {
+		err = writeLiteral(&buf, v, false)
+	}
+	return buf.String(), err
+}
+
+// afterIn reports whether the placeholder follows the IN keyword, a list
+// bound to IN ? is rendered as (a,b,...).
+func afterIn(prefix string) bool {
+	prefix = strings.TrimRight(prefix, " \t\r\n")
+	if len(prefix) < 2 || !strings.EqualFold(prefix[len(prefix)-2:], "IN") {
+		return false
+	}
+	if len(prefix) == 2 {
+		return true
+	}
+	b := prefix[len(prefix)-3]
+	return !(b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9')
+}
+
+// writeTypedLiteral marshals v as info and renders the marshalled value.
+func writeTypedLiteral(buf *strings.Builder, info gocql.TypeInfo, v interface{}, in bool) error {
+	if v == gocql.UnsetValue {
+		buf.WriteString("null")
+		return nil
+	}
+	data, err := gocql.Marshal(info, v)
+	if err != nil {
+		return err
+	}
+	return writeTypedData(buf, info, data, in)
+}
+
+// writeTypedData renders CQL representation of a value of type info.
+// Collections, tuples and UDTs are split into elements, so that null
+// elements are kept, other values are unmarshalled into the default Go type
+// of info.
+func writeTypedData(buf *strings.Builder, info gocql.TypeInfo, data []byte, in bool) error {
+	if data == nil {
+		buf.WriteString("null")
+		return nil
+	}
+
+	if _, ok := info.(gocql.VectorType); !ok && info.Version() > 2 {
+		switch info.Type() {
+		case gocql.TypeList, gocql.TypeSet:
+			open, closing := "[", "]"
+			if info.Type() == gocql.TypeSet {
+				open, closing = "{", "}"
+			}
+			if in {
+				open, closing = "(", ")"
+			}
+			elem := info.(gocql.CollectionType).Elem
+			return writeCollectionData(buf, data, 1, open, closing, func(buf *strings.Builder, i int, data []byte) error {
+				return writeTypedData(buf, elem, data, false)
+			})
+		case gocql.TypeMap:
+			ct := info.(gocql.CollectionType)
+			return writeCollectionData(buf, data, 2, "{", "}", func(buf *strings.Builder, i int, data []byte) error {
+				if i%2 == 0 {
+					if err := writeTypedData(buf, ct.Key, data, false); err != nil {
+						return err
+					}
+					buf.WriteString(": ")
+					return nil
+				}
+				return writeTypedData(buf, ct.Elem, data, false)
+			})
+		case gocql.TypeTuple:
+			tt := info.(gocql.TupleTypeInfo)
+			buf.WriteByte('(')
+			for i, t := range tt.Elems {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				var (
+					v   []byte
+					err error
+				)
+				v, data, err = readValue(data)
+				if err != nil {
+					return err
+				}
+				if err := writeTypedData(buf, t, v, false); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte(')')
+			return nil
+		case gocql.TypeUDT:
+			ut := info.(gocql.UDTTypeInfo)
+			buf.WriteByte('{')
+			for i, f := range ut.Elements {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				writeIdentifier(buf, f.Name)
+				buf.WriteString(": ")
+				var v []byte
+				if len(data) > 0 {
+					var err error
+					v, data, err = readValue(data)
+					if err != nil {
+						return err
+					}
+				}
+				if err := writeTypedData(buf, f.Type, v, false); err != nil {
+					return fmt.Errorf("field %q: %w", f.Name, err)
+				}
+			}
+			buf.WriteByte('}')
+			return nil
+		}
+	}
+
+	if vt, ok := info.(gocql.VectorType); ok {
+		p, err := vt.SubType.NewWithError()
+		if err != nil {
+			return err
+		}
+		v := reflect.New(reflect.SliceOf(reflect.TypeOf(p).Elem())).Elem()
+		if err := gocql.Unmarshal(info, data, v.Addr().Interface()); err != nil {
+			return err
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			data, err := gocql.Marshal(vt.SubType, v.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+			if err := writeTypedData(buf, vt.SubType, data, false); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+
+	if info.Type() == gocql.TypeBlob || info.Type() == gocql.TypeCustom {
+		buf.WriteString("0x")
+		buf.WriteString(hex.EncodeToString(data))
+		return nil
+	}
+
+	p, err := info.NewWithError()
+	if err != nil {
+		return err
+	}
+	if err := gocql.Unmarshal(info, data, p); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(p).Elem()
+
+	switch info.Type() {
+	case gocql.TypeDate:
+		if t, ok := v.Interface().(time.Time); ok {
+			writeString(buf, t.UTC().Format("2006-01-02"))
+			return nil
+		}
+	case gocql.TypeTime:
+		if d, ok := v.Interface().(time.Duration); ok {
+			writeTime(buf, d)
+			return nil
+		}
+	}
+	return writeLiteral(buf, v.Interface(), in)
+}
+
+// writeCollectionData renders a collection of n elements per entry, i.e.
+// 2 for maps. Map entries are sorted.
+func writeCollectionData(buf *strings.Builder, data []byte, n int, open, closing string,
+	elem func(buf *strings.Builder, i int, data []byte) error,
+) error {
+	if len(data) < 4 {
+		return errors.New("collection too short")
+	}
+	count := int(int32(binary.BigEndian.Uint32(data)))
+	if count < 0 {
+		return fmt.Errorf("invalid collection size %d", count)
+	}
+	data = data[4:]
+
+	entries := make([]string, count)
+	var b strings.Builder
+	for i := 0; i < count*n; i++ {
+		var (
+			v   []byte
+			err error
+		)
+		v, data, err = readValue(data)
+		if err != nil {
+			return err
+		}
+		if err := elem(&b, i, v); err != nil {
+			return err
+		}
+		if i%n == n-1 {
+			entries[i/n] = b.String()
+			b.Reset()
+		}
+	}
+	if n > 1 {
+		sort.Strings(entries)
+	}
+
+	buf.WriteString(open)
+	buf.WriteString(strings.Join(entries, ", "))
+	buf.WriteString(closing)
+	return nil
+}
+
+// readValue reads a value prefixed with int32 length, negative length is
+// a null value.
+func readValue(data []byte) (value, rest []byte, err error) {
+	if len(data) < 4 {
+		return nil, nil, errors.New("value too short")
+	}
+	n := int(int32(binary.BigEndian.Uint32(data)))
+	data = data[4:]
+	if n < 0 {
+		return nil, data, nil
+	}
+	if len(data) < n {
+		return nil, nil, fmt.Errorf("value too short, got %d bytes expected %d", len(data), n)
+	}
+	return data[:n:n], data[n:], nil
+}
+
+// writeLiteral renders v based on its Go type.
+func writeLiteral(buf *strings.Builder, v interface{}, in bool) error {
+	if v == nil || v == gocql.UnsetValue {
+		buf.WriteString("null")
+		return nil
+	}
+
+	switch v := v.(type) {
+	case udt:
+		return writeUDT(buf, v.value, v.mapper, v.codecs)
+	case codecValue:
+		if v.value.Kind() == reflect.Ptr && v.value.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return fmt.Errorf("%s has a codec, its type must be set in Renderer.Types", v.typ)
+	case string:
+		writeString(buf, v)
+		return nil
+	case []byte:
+		if v == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteString("0x")
+		buf.WriteString(hex.EncodeToString(v))
+		return nil
+	case time.Time:
+		writeString(buf, v.UTC().Format("2006-01-02T15:04:05.000Z"))
+		return nil
+	case time.Duration:
+		writeDuration(buf, 0, 0, int64(v))
+		return nil
+	case gocql.Duration:
+		writeDuration(buf, v.Months, v.Days, v.Nanoseconds)
+		return nil
+	case gocql.UUID:
+		buf.WriteString(v.String())
+		return nil
+	case net.IP:
+		writeString(buf, v.String())
+		return nil
+	case *big.Int:
+		if v == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteString(v.String())
+		return nil
+	case *inf.Dec:
+		if v == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteString(v.String())
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeLiteral(buf, rv.Elem().Interface(), in)
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(rv.Bool()))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(rv.Int(), 10))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString(strconv.FormatUint(rv.Uint(), 10))
+		return nil
+	case reflect.Float32:
+		writeFloat(buf, rv.Float(), 32)
+		return nil
+	case reflect.Float64:
+		writeFloat(buf, rv.Float(), 64)
+		return nil
+	case reflect.String:
+		writeString(buf, rv.String())
+		return nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			buf.WriteString("0x")
+			for i := 0; i < rv.Len(); i++ {
+				fmt.Fprintf(buf, "%02x", rv.Index(i).Uint())
+			}
+			return nil
+		}
+		open, closing := "[", "]"
+		if in {
+			open, closing = "(", ")"
+		}
+		buf.WriteString(open)
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeLiteral(buf, rv.Index(i).Interface(), false); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(closing)
+		return nil
+	case reflect.Map:
+		if rv.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		write := func(buf *strings.Builder, v reflect.Value) error {
+			return writeLiteral(buf, v.Interface(), false)
+		}
+		return writeMap(buf, rv, write, write)
+	case reflect.Struct:
+		if rv.Type().Implements(autoUDTInterface) {
+			return writeUDT(buf, rv, DefaultMapper, nil)
+		}
+	}
+
+	if s, ok := v.(fmt.Stringer); ok {
+		writeString(buf, s.String())
+		return nil
+	}
+	return fmt.Errorf("can't render %T without type information", v)
+}
+
+// writeUDT renders fields of v sorted by name.
+func writeUDT(buf *strings.Builder, v reflect.Value, mapper *reflectx.Mapper, codecs *CodecRegistry) error {
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+
+	var fields []*reflectx.FieldInfo
+	for _, fi := range mapper.TypeMap(v.Type()).Index {
+		if fi.Embedded || strings.Contains(fi.Path, ".") {
+			continue
+		}
+		fields = append(fields, fi)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Name < fields[j].Name
+	})
+
+	buf.WriteByte('{')
+	for i, fi := range fields {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		writeIdentifier(buf, fi.Name)
+		buf.WriteString(": ")
+
+		f := reflectx.FieldByIndexesReadOnly(v, fi.Index)
+		var err error
+		if f.Type().Implements(autoUDTInterface) {
+			err = writeUDT(buf, f, mapper, codecs)
+		} else {
+			err = writeLiteral(buf, codecs.wrapValue(f), false)
+		}
+		if err != nil {
+			return fmt.Errorf("field %q: %w", fi.Name, err)
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// writeMap renders map entries sorted by the rendered keys.
+func writeMap(buf *strings.Builder, v reflect.Value, key, elem func(*strings.Builder, reflect.Value) error) error {
+	type entry struct {
+		key  string
+		elem string
+	}
+	entries := make([]entry, 0, v.Len())
+
+	var b strings.Builder
+	iter := v.MapRange()
+	for iter.Next() {
+		b.Reset()
+		if err := key(&b, iter.Key()); err != nil {
+			return err
+		}
+		k := b.String()
+
+		b.Reset()
+		if err := elem(&b, iter.Value()); err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: k, elem: b.String()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	buf.WriteByte('{')
+	for i, e := range entries {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(e.key)
+		buf.WriteString(": ")
+		buf.WriteString(e.elem)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func writeString(buf *strings.Builder, s string) {
+	buf.WriteByte('\'')
+	buf.WriteString(strings.ReplaceAll(s, "'", "''"))
+	buf.WriteByte('\'')
+}
+
+// writeIdentifier writes name quoted if it's not a lowercase identifier.
+func writeIdentifier(buf *strings.Builder, name string) {
+	plain := name != ""
+	for i := 0; i < len(name) && plain; i++ {
+		b := name[i]
+		plain = b >= 'a' && b <= 'z' || b == '_' || i > 0 && b >= '0' && b <= '9'
+	}
+	if plain {
+		buf.WriteString(name)
+		return
+	}
+	buf.WriteByte('"')
+	buf.WriteString(strings.ReplaceAll(name, `"`, `""`))
+	buf.WriteByte('"')
+}
+
+func writeFloat(buf *strings.Builder, f float64, bitSize int) {
+	switch {
+	case math.IsNaN(f):
+		buf.WriteString("NaN")
+	case math.IsInf(f, 1):
+		buf.WriteString("Infinity")
+	case math.IsInf(f, -1):
+		buf.WriteString("-Infinity")
+	default:
+		buf.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+}
+
+var durationUnits = []struct {
+	unit string
+	d    time.Duration
+}{
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+	{"ns", time.Nanosecond},
+}
+
+// writeDuration writes a duration literal, i.e. 1mo2d3h4m5s6ms.
+func writeDuration(buf *strings.Builder, months, days int32, nanos int64) {
+	if months < 0 || days < 0 || nanos < 0 {
+		buf.WriteByte('-')
+	}
+
+	empty := true
+	write := func(n int64, unit string) {
+		if n == 0 {
+			return
+		}
+		buf.WriteString(strconv.FormatInt(n, 10))
+		buf.WriteString(unit)
+		empty = false
+	}
+	write(abs(int64(months)), "mo")
+	write(abs(int64(days)), "d")
+	n := abs(nanos)
+	for _, u := range durationUnits {
+		write(n/int64(u.d), u.unit)
+		n %= int64(u.d)
+	}
+
+	if empty {
+		buf.WriteString("0s")
+	}
+}
+
+// writeTime writes a time of day literal, i.e. '08:12:54.123456789'.
+func writeTime(buf *strings.Builder, d time.Duration) {
+	fmt.Fprintf(buf, "'%02d:%02d:%02d.%09d'",
+		d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second, d%time.Second)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,307 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package gocqlx
+
+import (
+	"math"
+	"math/big"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/inf.v0"
+)
+
+type renderAddress struct {
+	UDT
+	Street string
+	Zip    *int
+}
+
+type renderUser struct {
+	UDT
+	Name    string
+	Address renderAddress
+}
+
+// renderUUID is an array that gocql can't marshal as uuid without a codec.
+type renderUUID [16]byte
+
+func renderCodecRegistry() *CodecRegistry {
+	r := testCodecRegistry()
+	r.Register(renderUUID{}, Codec{
+		Marshal: func(info gocql.TypeInfo, value interface{}) ([]byte, error) {
+			return gocql.Marshal(info, gocql.UUID(value.(renderUUID)))
+		},
+		Unmarshal: func(info gocql.TypeInfo, data []byte, value interface{}) error {
+			return gocql.Unmarshal(info, data, (*gocql.UUID)(value.(*renderUUID)))
+		},
+	})
+	return r
+}
+
+func nativeType(t gocql.Type) gocql.TypeInfo {
+	return gocql.NewNativeType(4, t)
+}
+
+func TestLiteral(t *testing.T) {
+	ts := time.Date(2024, 2, 29, 13, 14, 15, 123456789, time.FixedZone("CET", 3600))
+	id := gocql.MustRandomUUID()
+	zip := 12345
+
+	table := []struct {
+		Name   string
+		Value  interface{}
+		Golden string
+	}{
+		{"nil", nil, "null"},
+		{"unset", gocql.UnsetValue, "null"},
+		{"nil pointer", (*int)(nil), "null"},
+		{"string", "O'Brien", "'O''Brien'"},
+		{"blob", []byte{0xca, 0xfe}, "0xcafe"},
+		{"nil blob", []byte(nil), "null"},
+		{"bool", true, "true"},
+		{"int", -42, "-42"},
+		{"pointer", &zip, "12345"},
+		{"uint", uint16(42), "42"},
+		{"float", 1.5, "1.5"},
+		{"nan", math.NaN(), "NaN"},
+		{"infinity", float32(math.Inf(-1)), "-Infinity"},
+		{"varint", big.NewInt(-1), "-1"},
+		{"decimal", inf.NewDec(12345, 2), "123.45"},
+		{"timestamp", ts, "'2024-02-29T12:14:15.123Z'"},
+		{"duration", 90*time.Minute + time.Millisecond, "1h30m1ms"},
+		{"zero duration", time.Duration(0), "0s"},
+		{"cql duration", gocql.Duration{Months: -14, Days: -3, Nanoseconds: -time.Second.Nanoseconds()}, "-14mo3d1s"},
+		{"uuid", id, id.String()},
+		{"inet", net.ParseIP("10.0.0.1"), "'10.0.0.1'"},
+		{"stringer", netip.MustParseAddr("::1"), "'::1'"},
+		{"list", []int{1, 2, 3}, "[1, 2, 3]"},
+		{"array", [2]string{"a", "b"}, "['a', 'b']"},
+		{"map", map[string]int{"b": 2, "a": 1}, "{'a': 1, 'b': 2}"},
+		{"udt", renderUser{Name: "Joe", Address: renderAddress{Street: "Main", Zip: &zip}}, "{address: {street: 'Main', zip: 12345}, name: 'Joe'}"},
+		{"udt pointer", &renderAddress{Street: "Main"}, "{street: 'Main', zip: null}"},
+		{"wrapped udt", makeUDT(reflect.ValueOf(renderAddress{Street: "Main", Zip: &zip}), DefaultMapper, nil, false), "{street: 'Main', zip: 12345}"},
+		{"nil codec", testCodecRegistry().wrap((*netip.Addr)(nil)), "null"},
+	}
+
+	for i := range table {
+		test := table[i]
+		t.Run(test.Name, func(t *testing.T) {
+			v, err := Literal(nil, test.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.Golden, v); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		if _, err := Literal(nil, struct{ A int }{}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("codec", func(t *testing.T) {
+		if _, err := Literal(nil, renderCodecRegistry().wrap(renderUUID(id))); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestLiteralTyped(t *testing.T) {
+	id := gocql.MustRandomUUID()
+	day := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	zip := 12345
+
+	udtInfo := gocql.NewUDTType(4, "address", "ks",
+		gocql.UDTField{Name: "street", Type: nativeType(gocql.TypeText)},
+		gocql.UDTField{Name: "zip", Type: nativeType(gocql.TypeInt)},
+		gocql.UDTField{Name: "Country", Type: nativeType(gocql.TypeText)},
+	)
+
+	table := []struct {
+		Name   string
+		Info   gocql.TypeInfo
+		Value  interface{}
+		Golden string
+	}{
+		{
+			Name:   "date",
+			Info:   nativeType(gocql.TypeDate),
+			Value:  day,
+			Golden: "'2024-02-29'",
+		},
+		{
+			Name:   "time",
+			Info:   nativeType(gocql.TypeTime),
+			Value:  13*time.Hour + 14*time.Minute + 15*time.Second + 123*time.Millisecond,
+			Golden: "'13:14:15.123000000'",
+		},
+		{
+			Name:   "timestamp from int",
+			Info:   nativeType(gocql.TypeTimestamp),
+			Value:  day.UnixMilli(),
+			Golden: "'2024-02-29T00:00:00.000Z'",
+		},
+		{
+			Name:   "varint",
+			Info:   nativeType(gocql.TypeVarint),
+			Value:  "123456789012345678901234567890",
+			Golden: "123456789012345678901234567890",
+		},
+		{
+			Name:   "inet codec",
+			Info:   nativeType(gocql.TypeInet),
+			Value:  testCodecRegistry().wrap(netip.MustParseAddr("10.0.0.1")),
+			Golden: "'10.0.0.1'",
+		},
+		{
+			Name:   "uuid codec",
+			Info:   nativeType(gocql.TypeUUID),
+			Value:  renderCodecRegistry().wrap(renderUUID(id)),
+			Golden: id.String(),
+		},
+		{
+			Name:   "null",
+			Info:   nativeType(gocql.TypeInt),
+			Value:  (*int)(nil),
+			Golden: "null",
+		},
+		{
+			Name: "set",
+			Info: gocql.CollectionType{
+				NativeType: nativeType(gocql.TypeSet).(gocql.NativeType),
+				Elem:       nativeType(gocql.TypeDate),
+			},
+			Value:  []time.Time{day},
+			Golden: "{'2024-02-29'}",
+		},
+		{
+			Name: "map",
+			Info: gocql.CollectionType{
+				NativeType: nativeType(gocql.TypeMap).(gocql.NativeType),
+				Key:        nativeType(gocql.TypeText),
+				Elem:       nativeType(gocql.TypeBlob),
+			},
+			Value:  map[string][]byte{"b": {2}, "a": {1}},
+			Golden: "{'a': 0x01, 'b': 0x02}",
+		},
+		{
+			Name: "vector",
+			Info: gocql.VectorType{
+				NativeType: gocql.NewCustomType(4, gocql.TypeCustom, "org.apache.cassandra.db.marshal.VectorType"),
+				SubType:    nativeType(gocql.TypeFloat),
+				Dimensions: 3,
+			},
+			Value:  []float32{0.5, 1, -2},
+			Golden: "[0.5, 1, -2]",
+		},
+		{
+			Name: "tuple",
+			Info: gocql.TupleTypeInfo{
+				NativeType: nativeType(gocql.TypeTuple).(gocql.NativeType),
+				Elems:      []gocql.TypeInfo{nativeType(gocql.TypeInt), nativeType(gocql.TypeText)},
+			},
+			Value:  []interface{}{1, nil},
+			Golden: "(1, null)",
+		},
+		{
+			Name:   "udt",
+			Info:   udtInfo,
+			Value:  makeUDT(reflect.ValueOf(renderAddress{Street: "Main", Zip: &zip}), DefaultMapper, nil, false),
+			Golden: `{street: 'Main', zip: 12345, "Country": null}`,
+		},
+		{
+			Name:   "udt map",
+			Info:   udtInfo,
+			Value:  map[string]interface{}{"street": "Main"},
+			Golden: `{street: 'Main', zip: null, "Country": null}`,
+		},
+	}
+
+	for i := range table {
+		test := table[i]
+		t.Run(test.Name, func(t *testing.T) {
+			v, err := Literal(test.Info, test.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.Golden, v); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	const stmt = "SELECT * FROM users WHERE id IN ? AND tags CONTAINS ? AND login='?' AND password=? AND token=(?,?) -- ?\n"
+
+	r := Renderer{
+		Types: map[string]gocql.TypeInfo{
+			"tags": nativeType(gocql.TypeText),
+		},
+		Redact: []string{"password", "token"},
+	}
+
+	names := []string{"id", "tags", "password", "token[0]", "token[1]"}
+	values := []interface{}{[]int{1, 2}, "admin", "secret", 1, 2}
+
+	v, err := r.Render(stmt, names, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := "SELECT * FROM users WHERE id IN (1, 2) AND tags CONTAINS 'admin' AND login='?' AND password='***' AND token=('***','***') -- ?\n"
+	if diff := cmp.Diff(golden, v); diff != "" {
+		t.Fatal(diff)
+	}
+
+	t.Run("without names", func(t *testing.T) {
+		v, err := Render("UPDATE t SET pin=? WHERE id=?", nil, []interface{}{[]int{1}, 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff("UPDATE t SET pin=[1] WHERE id=2", v); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		if _, err := Render("SELECT * FROM t WHERE id=?", nil, nil); err == nil {
+			t.Fatal("expected error")
+		}
+		if _, err := Render("SELECT * FROM t WHERE id=?", []string{"id", "x"}, []interface{}{1}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestAfterIn(t *testing.T) {
+	table := []struct {
+		Prefix string
+		Golden bool
+	}{
+		{"id IN ", true},
+		{"id in", true},
+		{"(a,b) IN ", true},
+		{"id=", false},
+		{"login IN", true},
+		{"pin=", false},
+		{"WHERE pin ", false},
+		{"IN", true},
+	}
+
+	for _, test := range table {
+		if v := afterIn(test.Prefix); v != test.Golden {
+			t.Errorf("afterIn(%q)=%v expected %v", test.Prefix, v, test.Golden)
+		}
+	}
+}
@@ -17,9 +17,25 @@ import (
 // The default mapper uses `db` tag and automatically converts struct field
 // names to snake case. If needed package reflectx provides constructors
 // for other types of mappers.
+// Codecs, if set, marshal and unmarshal values of the registered Go types.
+// BindTransformer and ScanTransformer, if set, are used by queries instead of
+// DefaultBindTransformer and DefaultScanTransformer.
+// Inspector, if set, is called with every query and batch of the session
+// right before it's executed, see qb.Detector.
 type Session struct {
 	*gocql.Session
-	Mapper *reflectx.Mapper
+	Mapper          *reflectx.Mapper
+	Codecs          *CodecRegistry
+	BindTransformer Transformer
+	ScanTransformer ScanTransformer
+	Inspector       Inspector
+}
+
+// Inspector inspects queries and batches before they are executed, i.e. to
+// report anti-patterns. It must not modify them.
+type Inspector interface {
+	InspectQuery(q *Queryx)
+	InspectBatch(b *Batch)
 }
 
 // NewSession wraps existing gocql.session.
@@ -47,11 +63,14 @@ func WrapSession(session *gocql.Session, err error) (Session, error) {
 // a query, see the "Query" function .
 func (s Session) ContextQuery(ctx context.Context, stmt string, names []string) *Queryx {
 	return &Queryx{
-		Query:  s.Session.Query(stmt).WithContext(ctx),
-		Names:  names,
-		Mapper: s.Mapper,
-		tr:     DefaultBindTransformer,
-		strict: DefaultStrict,
+		Query:     s.Session.Query(stmt).WithContext(ctx),
+		Names:     names,
+		Mapper:    s.Mapper,
+		Codecs:    s.Codecs,
+		tr:        s.bindTransformer(),
+		scanTr:    s.scanTransformer(),
+		inspector: s.Inspector,
+		strict:    DefaultStrict,
 	}
 }
 
@@ -62,12 +81,29 @@ func (s Session) ContextQuery(ctx context.Context, stmt string, names []string)
 // binding.
 func (s Session) Query(stmt string, names []string) *Queryx {
 	return &Queryx{
-		Query:  s.Session.Query(stmt),
-		Names:  names,
-		Mapper: s.Mapper,
-		tr:     DefaultBindTransformer,
-		strict: DefaultStrict,
+		Query:     s.Session.Query(stmt),
+		Names:     names,
+		Mapper:    s.Mapper,
+		Codecs:    s.Codecs,
+		tr:        s.bindTransformer(),
+		scanTr:    s.scanTransformer(),
+		inspector: s.Inspector,
+		strict:    DefaultStrict,
+	}
+}
+
+func (s Session) bindTransformer() Transformer {
+	if s.BindTransformer != nil {
+		return s.BindTransformer
+	}
+	return DefaultBindTransformer
+}
+
+func (s Session) scanTransformer() ScanTransformer {
+	if s.ScanTransformer != nil {
+		return s.ScanTransformer
 	}
+	return DefaultScanTransformer
 }
 
 // ExecStmt creates query and executes the given statement.
@@ -6,6 +6,10 @@ package table
 
 import (
 	"context"
+	"reflect"
+	"slices"
+
+	"github.com/scylladb/go-reflectx"
 
 	"github.com/scylladb/gocqlx/v3"
 	"github.com/scylladb/gocqlx/v3/qb"
@@ -36,6 +40,9 @@ type Table struct {
 	get    cql
 	sel    cql
 	insert cql
+
+	// writable columns of a model table, see WithModel.
+	writable []string
 }
 
 // New creates new Table based on table schema read from Metadata.
@@ -65,6 +72,60 @@ func New(m Metadata) *Table {
 	return t
 }
 
+// WithModel returns a copy of the table that writes only columns mapped to
+// fields of the model struct by gocqlx.DefaultMapper. Insert and
+// InsertBuilder skip columns without a field, including fields tagged
+// `db:"-"`, and fields with the readonly option i.e. `db:"updated,readonly"`.
+// Update and UpdateBuilder called without columns update all the written
+// columns that are not part of the primary key. If session uses a custom
+// Mapper use WithModelMapper. A table without a model writes all columns
+// of the metadata, the readonly option is ignored.
+func (t *Table) WithModel(model interface{}) *Table {
+	return t.WithModelMapper(model, gocqlx.DefaultMapper)
+}
+
+// WithModelMapper is like WithModel but maps model fields to columns with
+// the given mapper, it should be the Mapper of the session.
+func (t *Table) WithModelMapper(model interface{}, mapper *reflectx.Mapper) *Table {
+	tm := mapper.TypeMap(reflect.TypeOf(model))
+
+	c := *t
+	c.writable = make([]string, 0, len(t.metadata.Columns))
+	for _, name := range t.metadata.Columns {
+		fi, ok := tm.Names[name]
+		if !ok {
+			continue
+		}
+		if _, ok := fi.Options[gocqlx.ReadOnlyOption]; ok {
+			continue
+		}
+		c.writable = append(c.writable, name)
+	}
+	c.insert.stmt, c.insert.names = qb.Insert(t.metadata.Name).Columns(c.writable...).ToCql()
+
+	return &c
+}
+
+// insertColumns returns columns written by insert.
+func (t *Table) insertColumns() []string {
+	if t.writable != nil {
+		return t.writable
+	}
+	return t.metadata.Columns
+}
+
+// updateColumns returns columns written by update without columns, it's
+// empty unless the table has a model.
+func (t *Table) updateColumns() []string {
+	var v []string
+	for _, name := range t.writable {
+		if !slices.Contains(t.metadata.PartKey, name) && !slices.Contains(t.metadata.SortKey, name) {
+			v = append(v, name)
+		}
+	}
+	return v
+}
+
 // Metadata returns copy of table metadata.
 func (t *Table) Metadata() Metadata {
 	return t.metadata
@@ -141,6 +202,19 @@ func (t *Table) SelectBuilder(columns ...string) *qb.SelectBuilder {
 	return qb.Select(t.metadata.Name).Columns(columns...).Where(t.partKeyCmp...)
 }
 
+// SelectAfterBuilder returns a builder initialised to select by partition
+// key rows with sort key greater than the bound one, i.e.
+// WHERE a=? AND (b,c)>(?,?). It can be used to resume iteration over
+// a partition from the last returned row. If there is no sort key it's
+// equivalent to SelectBuilder.
+func (t *Table) SelectAfterBuilder(columns ...string) *qb.SelectBuilder {
+	b := t.SelectBuilder(columns...)
+	if len(t.metadata.SortKey) > 0 {
+		b.Where(qb.MultiColumn(t.metadata.SortKey...).Gt())
+	}
+	return b
+}
+
 // SelectAll returns select * statement.
 func (t *Table) SelectAll() (stmt string, names []string) {
 	return qb.Select(t.metadata.Name).ToCql()
@@ -163,7 +237,7 @@ func (t *Table) InsertQueryContext(ctx context.Context, session gocqlx.Session)
 
 // InsertBuilder returns a builder initialised with all columns.
 func (t *Table) InsertBuilder() *qb.InsertBuilder {
-	return qb.Insert(t.metadata.Name).Columns(t.metadata.Columns...)
+	return qb.Insert(t.metadata.Name).Columns(t.insertColumns()...)
 }
 
 // Update returns update by primary key statement.
@@ -183,6 +257,9 @@ func (t *Table) UpdateQueryContext(ctx context.Context, session gocqlx.Session,
 
 // UpdateBuilder returns a builder initialised to update by primary key statement.
 func (t *Table) UpdateBuilder(columns ...string) *qb.UpdateBuilder {
+	if len(columns) == 0 {
+		columns = t.updateColumns()
+	}
 	return qb.Update(t.metadata.Name).Set(columns...).Where(t.primaryKeyCmp...)
 }
 
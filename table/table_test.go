@@ -5,10 +5,12 @@
 package table
 
 import (
+	"strings"
 	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/scylladb/go-reflectx"
 
 	"github.com/scylladb/gocqlx/v3/qb"
 )
@@ -125,6 +127,46 @@ func TestTableSelect(t *testing.T) {
 	}
 }
 
+func TestTableSelectAfter(t *testing.T) {
+	table := []struct {
+		M Metadata
+		C []string
+		N []string
+		S string
+	}{
+		{
+			M: Metadata{
+				Name:    "tbl",
+				Columns: []string{"a", "b", "c", "d"},
+				PartKey: []string{"a"},
+				SortKey: []string{"b", "c"},
+			},
+			C: []string{"d"},
+			N: []string{"a", "b", "c"},
+			S: "SELECT d FROM tbl WHERE a=? AND (b,c)>(?,?) ",
+		},
+		{
+			M: Metadata{
+				Name:    "tbl",
+				Columns: []string{"a", "b"},
+				PartKey: []string{"a"},
+			},
+			N: []string{"a"},
+			S: "SELECT * FROM tbl WHERE a=? ",
+		},
+	}
+
+	for _, test := range table {
+		stmt, names := New(test.M).SelectAfterBuilder(test.C...).ToCql()
+		if diff := cmp.Diff(test.S, stmt); diff != "" {
+			t.Error(diff)
+		}
+		if diff := cmp.Diff(test.N, names); diff != "" {
+			t.Error(diff, names)
+		}
+	}
+}
+
 func TestTableInsert(t *testing.T) {
 	table := []struct {
 		M Metadata
@@ -196,6 +238,79 @@ func TestTableUpdate(t *testing.T) {
 	}
 }
 
+func TestTableWithModel(t *testing.T) {
+	type model struct {
+		A       string
+		B       string
+		C       string `db:"c,omitempty"`
+		D       string `db:"d,readonly"`
+		Skipped string `db:"-"`
+	}
+	m := Metadata{
+		Name:    "tbl",
+		Columns: []string{"a", "b", "c", "d", "skipped"},
+		PartKey: []string{"a"},
+		SortKey: []string{"b"},
+	}
+	base := New(m)
+	tbl := base.WithModel(model{})
+
+	stmt, names := tbl.Insert()
+	if diff := cmp.Diff("INSERT INTO tbl (a,b,c) VALUES (?,?,?) ", stmt); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, names); diff != "" {
+		t.Error(diff)
+	}
+
+	stmt, _ = tbl.InsertBuilder().ToCql()
+	if diff := cmp.Diff("INSERT INTO tbl (a,b,c) VALUES (?,?,?) ", stmt); diff != "" {
+		t.Error(diff)
+	}
+
+	stmt, names = tbl.Update()
+	if diff := cmp.Diff("UPDATE tbl SET c=? WHERE a=? AND b=? ", stmt); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]string{"c", "a", "b"}, names); diff != "" {
+		t.Error(diff)
+	}
+
+	stmt, _ = tbl.Update("d")
+	if diff := cmp.Diff("UPDATE tbl SET d=? WHERE a=? AND b=? ", stmt); diff != "" {
+		t.Error(diff)
+	}
+
+	// the original table is not modified
+	stmt, _ = base.Insert()
+	if diff := cmp.Diff("INSERT INTO tbl (a,b,c,d,skipped) VALUES (?,?,?,?,?) ", stmt); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestTableWithModelMapper(t *testing.T) {
+	type model struct {
+		A      string
+		BValue string
+		C      string `json:"c,readonly"`
+	}
+	m := Metadata{
+		Name:    "tbl",
+		Columns: []string{"a", "bvalue", "b_value", "c"},
+		PartKey: []string{"a"},
+	}
+	mapper := reflectx.NewMapperFunc("json", strings.ToLower)
+	tbl := New(m).WithModelMapper(model{}, mapper)
+
+	stmt, names := tbl.Insert()
+	if diff := cmp.Diff("INSERT INTO tbl (a,bvalue) VALUES (?,?) ", stmt); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]string{"a", "bvalue"}, names); diff != "" {
+		t.Error(diff)
+	}
+}
+
 func TestTableDelete(t *testing.T) {
 	table := []struct {
 		M Metadata
@@ -0,0 +1,85 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package table
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gocql/gocql"
+
+	"github.com/scylladb/gocqlx/v3/qb"
+)
+
+// Schema returns the metadata as qb.Schema, it has no counter or indexed
+// columns.
+func (m Metadata) Schema() qb.Schema {
+	return qb.Schema{
+		Columns: m.Columns,
+		PartKey: m.PartKey,
+		SortKey: m.SortKey,
+	}
+}
+
+// SchemaOf returns qb.Schema of a table read from the cluster, it includes
+// counter, list and indexed columns.
+func SchemaOf(m *gocql.TableMetadata) qb.Schema {
+	var s qb.Schema
+	for _, c := range m.PartitionKey {
+		s.PartKey = append(s.PartKey, c.Name)
+	}
+	for _, c := range m.ClusteringColumns {
+		s.SortKey = append(s.SortKey, c.Name)
+	}
+
+	columns := m.OrderedColumns
+	if len(columns) == 0 {
+		for name := range m.Columns {
+			columns = append(columns, name)
+		}
+		sort.Strings(columns)
+	}
+	for _, name := range columns {
+		c, ok := m.Columns[name]
+		if !ok {
+			continue
+		}
+		s.Columns = append(s.Columns, name)
+		if c.Type == "counter" {
+			s.Counters = append(s.Counters, name)
+		}
+		if strings.HasPrefix(c.Type, "list<") {
+			s.Lists = append(s.Lists, name)
+		}
+		if c.Index.Name != "" {
+			s.Indexed = append(s.Indexed, name)
+		}
+	}
+	return s
+}
+
+// SchemaFunc returns a function that reads table schema from the cluster
+// metadata, it can be used as qb.Detector Schema.
+func SchemaFunc(session *gocql.Session) func(keyspace, name string) (qb.Schema, error) {
+	return func(keyspace, name string) (qb.Schema, error) {
+		m, err := session.TableMetadata(keyspace, name)
+		if err != nil {
+			return qb.Schema{}, err
+		}
+		return SchemaOf(m), nil
+	}
+}
+
+// Validate checks the statement built by b against the table schema, see
+// qb.Validate.
+func (t *Table) Validate(b qb.Builder) error {
+	return qb.Validate(b, t.metadata.Schema())
+}
+
+// ValidateCql checks the statement and names against the table schema, see
+// qb.ValidateCql.
+func (t *Table) ValidateCql(stmt string, names []string) error {
+	return qb.ValidateCql(stmt, names, t.metadata.Schema())
+}
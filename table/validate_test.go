@@ -0,0 +1,64 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package table
+
+import (
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/scylladb/gocqlx/v3/qb"
+)
+
+func TestSchemaOf(t *testing.T) {
+	id := &gocql.ColumnMetadata{Name: "id", Type: "uuid", Kind: gocql.ColumnPartitionKey}
+	ts := &gocql.ColumnMetadata{Name: "ts", Type: "timestamp", Kind: gocql.ColumnClusteringKey}
+	m := &gocql.TableMetadata{
+		Name: "tbl",
+		Columns: map[string]*gocql.ColumnMetadata{
+			"id":    id,
+			"ts":    ts,
+			"views": {Name: "views", Type: "counter", Kind: gocql.ColumnRegular},
+			"tags":  {Name: "tags", Type: "list<text>", Kind: gocql.ColumnRegular},
+			"name":  {Name: "name", Type: "text", Kind: gocql.ColumnRegular, Index: gocql.ColumnIndexMetadata{Name: "tbl_name_idx"}},
+		},
+		PartitionKey:      []*gocql.ColumnMetadata{id},
+		ClusteringColumns: []*gocql.ColumnMetadata{ts},
+	}
+
+	golden := qb.Schema{
+		Columns:  []string{"id", "name", "tags", "ts", "views"},
+		PartKey:  []string{"id"},
+		SortKey:  []string{"ts"},
+		Counters: []string{"views"},
+		Lists:    []string{"tags"},
+		Indexed:  []string{"name"},
+	}
+	if diff := cmp.Diff(golden, SchemaOf(m)); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestTableValidate(t *testing.T) {
+	tbl := New(Metadata{
+		Name:    "tbl",
+		Columns: []string{"a", "b", "c", "d"},
+		PartKey: []string{"a"},
+		SortKey: []string{"b"},
+	})
+
+	for _, b := range []qb.Builder{tbl.GetBuilder(), tbl.SelectBuilder("c"), tbl.InsertBuilder(), tbl.UpdateBuilder("c"), tbl.DeleteBuilder()} {
+		if err := tbl.Validate(b); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := tbl.Validate(tbl.SelectBuilder("e")); err == nil {
+		t.Error("expected error")
+	}
+	if err := tbl.ValidateCql("SELECT * FROM tbl WHERE b=?", nil); err == nil {
+		t.Error("expected error")
+	}
+}
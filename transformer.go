@@ -5,6 +5,7 @@
 package gocqlx
 
 import (
+	"fmt"
 	"reflect"
 
 	"github.com/gocql/gocql"
@@ -15,7 +16,7 @@ type Transformer func(name string, val interface{}) interface{}
 
 // DefaultBindTransformer just do nothing.
 //
-// A custom transformer can always be set per Query.
+// A custom transformer can always be set per Session and Query.
 var DefaultBindTransformer Transformer
 
 // UnsetEmptyTransformer unsets all empty parameters.
@@ -28,3 +29,106 @@ var UnsetEmptyTransformer = func(name string, val interface{}) interface{} {
 	}
 	return val
 }
+
+// ChainTransformers returns a Transformer calling the transformers in order,
+// each one is given the value returned by the previous one. Nil transformers
+// are skipped.
+func ChainTransformers(trs ...Transformer) Transformer {
+	return func(name string, val interface{}) interface{} {
+		for _, tr := range trs {
+			if tr != nil {
+				val = tr(name, val)
+			}
+		}
+		return val
+	}
+}
+
+// ColumnTransformer returns a Transformer calling the transformer registered
+// for the named parameter, other parameters are left intact.
+func ColumnTransformer(m map[string]Transformer) Transformer {
+	return func(name string, val interface{}) interface{} {
+		if tr := m[name]; tr != nil {
+			return tr(name, val)
+		}
+		return val
+	}
+}
+
+// ScanTransformer transforms the value scanned from the named column to
+// another value. The returned value must be assignable to the destination,
+// returning an error stops the iteration.
+type ScanTransformer func(name string, val interface{}) (interface{}, error)
+
+// DefaultScanTransformer just do nothing.
+//
+// A custom transformer can always be set per Session, Query and Iter.
+var DefaultScanTransformer ScanTransformer
+
+// ChainScanTransformers returns a ScanTransformer calling the transformers
+// in order, each one is given the value returned by the previous one. Nil
+// transformers are skipped.
+func ChainScanTransformers(trs ...ScanTransformer) ScanTransformer {
+	return func(name string, val interface{}) (interface{}, error) {
+		var err error
+		for _, tr := range trs {
+			if tr == nil {
+				continue
+			}
+			if val, err = tr(name, val); err != nil {
+				return nil, err
+			}
+		}
+		return val, nil
+	}
+}
+
+// ColumnCodec is a pair of functions converting a column value on bind and
+// scan, i.e. encrypting and decrypting a column. Any of the functions can be
+// nil.
+type ColumnCodec struct {
+	Bind func(val interface{}) interface{}
+	Scan func(val interface{}) (interface{}, error)
+}
+
+// ColumnCodecs maps column names to codecs.
+type ColumnCodecs map[string]ColumnCodec
+
+// BindTransformer returns a Transformer applying the codecs on bind.
+func (c ColumnCodecs) BindTransformer() Transformer {
+	return func(name string, val interface{}) interface{} {
+		if codec, ok := c[name]; ok && codec.Bind != nil {
+			return codec.Bind(val)
+		}
+		return val
+	}
+}
+
+// ScanTransformer returns a ScanTransformer applying the codecs on scan.
+func (c ColumnCodecs) ScanTransformer() ScanTransformer {
+	return func(name string, val interface{}) (interface{}, error) {
+		if codec, ok := c[name]; ok && codec.Scan != nil {
+			return codec.Scan(val)
+		}
+		return val, nil
+	}
+}
+
+// transformScanned applies the scan transformer to the scanned value v of
+// the named column and stores the result in v.
+func transformScanned(tr ScanTransformer, name string, v reflect.Value) error {
+	val, err := tr(name, v.Interface())
+	if err != nil {
+		return fmt.Errorf("transform column %q: %w", name, err)
+	}
+	if val == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(val)
+	if !rv.Type().AssignableTo(v.Type()) {
+		return fmt.Errorf("transform column %q: got %s expected %s", name, rv.Type(), v.Type())
+	}
+	v.Set(rv)
+	return nil
+}
@@ -0,0 +1,201 @@
+// Copyright (C) 2017 ScyllaDB
+// Use of this source code is governed by a ALv2-style
+// license that can be found in the LICENSE file.
+
+package gocqlx
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestColumnCodecs(t *testing.T) {
+	codecs := ColumnCodecs{
+		"name": {
+			Bind: func(val interface{}) interface{} { return strings.ToUpper(val.(string)) },
+			Scan: func(val interface{}) (interface{}, error) { return strings.ToLower(val.(string)), nil },
+		},
+		"bind_only": {
+			Bind: func(val interface{}) interface{} { return "bound" },
+		},
+	}
+
+	bind := codecs.BindTransformer()
+	scan := codecs.ScanTransformer()
+
+	if v := bind("name", "foo"); v != "FOO" {
+		t.Errorf("bind(name)=%v expected FOO", v)
+	}
+	if v := bind("other", "foo"); v != "foo" {
+		t.Errorf("bind(other)=%v expected foo", v)
+	}
+	if v, err := scan("name", "FOO"); err != nil || v != "foo" {
+		t.Errorf("scan(name)=%v, %v expected foo", v, err)
+	}
+	if v, err := scan("bind_only", "FOO"); err != nil || v != "FOO" {
+		t.Errorf("scan(bind_only)=%v, %v expected FOO", v, err)
+	}
+}
+
+func TestIterxTransformFields(t *testing.T) {
+	type Inner struct {
+		Email string
+	}
+	type Row struct {
+		Name string
+		Age  int
+		Tags []string
+		Inner
+	}
+
+	tr := func(name string, val interface{}) (interface{}, error) {
+		switch name {
+		case "name", "email":
+			return strings.ToLower(val.(string)), nil
+		case "age":
+			return val.(int) + 1, nil
+		case "tags":
+			return nil, nil
+		default:
+			return val, nil
+		}
+	}
+
+	iter := &Iterx{Mapper: DefaultMapper, tr: tr}
+	iter.columns = []string{"name", "age", "tags", "email", "unknown"}
+	v := &Row{Name: "FOO", Age: 1, Tags: []string{"a"}, Inner: Inner{Email: "FOO@BAR.COM"}}
+	iter.fields = iter.Mapper.TraversalsByName(reflect.TypeOf(v), iter.columns)
+
+	if err := iter.transformFields(reflect.ValueOf(v)); err != nil {
+		t.Fatal(err)
+	}
+	golden := &Row{Name: "foo", Age: 2, Inner: Inner{Email: "foo@bar.com"}}
+	if diff := cmp.Diff(golden, v); diff != "" {
+		t.Fatal(diff)
+	}
+
+	t.Run("not assignable", func(t *testing.T) {
+		iter.tr = func(name string, val interface{}) (interface{}, error) {
+			return "1", nil
+		}
+		err := iter.transformFields(reflect.ValueOf(v))
+		if err == nil || err.Error() != `transform column "age": got string expected int` {
+			t.Fatalf("transformFields() error=%v", err)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		iter.tr = func(name string, val interface{}) (interface{}, error) {
+			return nil, errors.New("boom")
+		}
+		err := iter.transformFields(reflect.ValueOf(v))
+		if err == nil || err.Error() != `transform column "name": boom` {
+			t.Fatalf("transformFields() error=%v", err)
+		}
+	})
+}
+
+func TestIterxTransformMap(t *testing.T) {
+	iter := &Iterx{tr: func(name string, val interface{}) (interface{}, error) {
+		if name == "[applied]" {
+			return nil, errors.New("transform [applied]")
+		}
+		if name == "name" {
+			return strings.ToLower(val.(string)), nil
+		}
+		return val, nil
+	}}
+
+	m := map[string]interface{}{"name": "FOO", "age": 1, "[applied]": true}
+	if err := iter.transformMap(m); err != nil {
+		t.Fatal(err)
+	}
+	golden := map[string]interface{}{"name": "foo", "age": 1, "[applied]": true}
+	if diff := cmp.Diff(golden, m); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestChainTransformers(t *testing.T) {
+	upper := func(name string, val interface{}) interface{} { return strings.ToUpper(val.(string)) }
+	suffix := func(name string, val interface{}) interface{} { return val.(string) + "_" + name }
+
+	tr := ChainTransformers(upper, nil, suffix, UnsetEmptyTransformer)
+	if v := tr("foo", "bar"); v != "BAR_foo" {
+		t.Errorf("tr()=%v expected BAR_foo", v)
+	}
+
+	tr = ChainTransformers(UnsetEmptyTransformer, ColumnTransformer(map[string]Transformer{"foo": upper}))
+	table := []struct {
+		Name   string
+		Val    interface{}
+		Golden interface{}
+	}{
+		{"foo", "bar", "BAR"},
+		{"baz", "bar", "bar"},
+		{"baz", 0, gocql.UnsetValue},
+	}
+	for _, test := range table {
+		if v := tr(test.Name, test.Val); v != test.Golden {
+			t.Errorf("tr(%q, %v)=%v expected %v", test.Name, test.Val, v, test.Golden)
+		}
+	}
+}
+
+func TestChainScanTransformers(t *testing.T) {
+	lower := func(name string, val interface{}) (interface{}, error) { return strings.ToLower(val.(string)), nil }
+	fail := func(name string, val interface{}) (interface{}, error) { return nil, errors.New("boom") }
+
+	if v, err := ChainScanTransformers(lower, nil)("foo", "BAR"); err != nil || v != "bar" {
+		t.Errorf("tr()=%v, %v expected bar", v, err)
+	}
+	if _, err := ChainScanTransformers(fail, lower)("foo", "BAR"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestQueryxWithColumnCodecs(t *testing.T) {
+	q := &Queryx{
+		tr: func(name string, val interface{}) interface{} { return val.(string) + "_bind" },
+		scanTr: func(name string, val interface{}) (interface{}, error) {
+			return val.(string) + "_scan", nil
+		},
+	}
+	q.WithColumnCodecs(ColumnCodecs{
+		"secret": {
+			Bind: func(val interface{}) interface{} { return strings.ToUpper(val.(string)) },
+			Scan: func(val interface{}) (interface{}, error) { return strings.ToUpper(val.(string)), nil },
+		},
+	})
+
+	if v := q.tr("secret", "foo"); v != "FOO_BIND" {
+		t.Errorf("tr()=%v expected FOO_BIND", v)
+	}
+	if v := q.tr("other", "foo"); v != "foo_bind" {
+		t.Errorf("tr()=%v expected foo_bind", v)
+	}
+	if v, err := q.scanTr("secret", "foo"); err != nil || v != "FOO_SCAN" {
+		t.Errorf("scanTr()=%v, %v expected FOO_SCAN", v, err)
+	}
+}
+
+func TestSessionTransformers(t *testing.T) {
+	defer func(tr Transformer) {
+		DefaultBindTransformer = tr
+	}(DefaultBindTransformer)
+	DefaultBindTransformer = UnsetEmptyTransformer
+
+	var s Session
+	if v := s.bindTransformer()("foo", ""); v != gocql.UnsetValue {
+		t.Errorf("bindTransformer() does not use DefaultBindTransformer")
+	}
+	s.BindTransformer = func(name string, val interface{}) interface{} { return val }
+	if v := s.bindTransformer()("foo", ""); v != "" {
+		t.Errorf("bindTransformer() does not use Session.BindTransformer")
+	}
+}
@@ -25,14 +25,18 @@ var (
 
 type udt struct {
 	field  map[string]reflect.Value
+	mapper *reflectx.Mapper
+	codecs *CodecRegistry
 	value  reflect.Value
 	strict bool
 }
 
-func makeUDT(value reflect.Value, mapper *reflectx.Mapper, strict bool) udt {
+func makeUDT(value reflect.Value, mapper *reflectx.Mapper, codecs *CodecRegistry, strict bool) udt {
 	return udt{
 		value:  value,
 		field:  mapper.FieldMap(value),
+		mapper: mapper,
+		codecs: codecs,
 		strict: strict,
 	}
 }
@@ -40,7 +44,7 @@ func makeUDT(value reflect.Value, mapper *reflectx.Mapper, strict bool) udt {
 func (u udt) MarshalUDT(name string, info gocql.TypeInfo) ([]byte, error) {
 	value, ok := u.field[name]
 	if ok {
-		return gocql.Marshal(info, value.Interface())
+		return gocql.Marshal(info, u.codecs.wrapValue(value))
 	}
 	if !u.strict {
 		return nil, nil
@@ -51,7 +55,7 @@ func (u udt) MarshalUDT(name string, info gocql.TypeInfo) ([]byte, error) {
 func (u udt) UnmarshalUDT(name string, info gocql.TypeInfo, data []byte) error {
 	value, ok := u.field[name]
 	if ok {
-		return gocql.Unmarshal(info, data, value.Addr().Interface())
+		return gocql.Unmarshal(info, data, u.codecs.wrapValue(value.Addr()))
 	}
 	if !u.strict {
 		return nil
@@ -59,19 +63,21 @@ func (u udt) UnmarshalUDT(name string, info gocql.TypeInfo, data []byte) error {
 	return fmt.Errorf("missing name %q in %s", name, u.value.Type())
 }
 
-// udtWrapValue adds UDT wrapper if needed.
-func udtWrapValue(value reflect.Value, mapper *reflectx.Mapper, strict bool) interface{} {
+// udtWrapValue adds UDT or codec wrapper if needed.
+func udtWrapValue(value reflect.Value, mapper *reflectx.Mapper, codecs *CodecRegistry, strict bool) interface{} {
 	if value.Type().Implements(autoUDTInterface) {
-		return makeUDT(value, mapper, strict)
+		return makeUDT(value, mapper, codecs, strict)
 	}
-	return value.Interface()
+	return codecs.wrapValue(value)
 }
 
-// udtWrapSlice adds UDT wrapper if needed.
-func udtWrapSlice(mapper *reflectx.Mapper, strict bool, v []interface{}) []interface{} {
+// udtWrapSlice adds UDT or codec wrapper if needed.
+func udtWrapSlice(mapper *reflectx.Mapper, codecs *CodecRegistry, strict bool, v []interface{}) []interface{} {
 	for i := range v {
 		if _, ok := v[i].(UDT); ok {
-			v[i] = makeUDT(reflect.ValueOf(v[i]), mapper, strict)
+			v[i] = makeUDT(reflect.ValueOf(v[i]), mapper, codecs, strict)
+		} else {
+			v[i] = codecs.wrap(v[i])
 		}
 	}
 	return v